@@ -0,0 +1,530 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/MathiasMantai/sql2interface/src"
+	"gopkg.in/yaml.v3"
+)
+
+// version is set at build time with -ldflags "-X main.version=<version>".
+var version = "dev"
+
+const usage = `Usage: s2i [command] [flags]
+
+Commands:
+  generate  convert the sql files and write the output files (default)
+  check     fail with a diff if the generated files on disk are out of date
+  init      write a commented s2iconfig.yaml, scanning the sql files in -i if given
+  inspect   print the effective configuration after all overrides
+  dump      print the parsed tables and combined structures as JSON, see -input-schema
+  watch     regenerate the output files whenever a sql file or the configuration changes
+  version   print the version
+
+Flags:
+  -c, -config string   path to the configuration file, the format is detected by the extension
+                       (default the first of s2iconfig.yaml, .yml, .json and .toml that exists)
+  -i, -input string    override the input directory
+  -input-schema string convert the tables of a JSON file written by dump instead of the input directory
+  -o, -output string   override the output directory of all targets
+  -p, -profile string  apply a profile from the profiles section or s2iconfig.<profile>.yaml (default $S2I_PROFILE)
+  -targets string      comma separated list of targets to generate, e.g. "go,typescript"
+  -set key=value       override a single configuration value, can be repeated
+                       (e.g. -set output.go.package_name=models -set "ignore_columns[users.sql]=[created_at]")
+  -q, -quiet           only print errors
+  -v, -verbose         print additional information, e.g. ignored columns and arbitrary fields
+  -debug               print every parsed column in addition to -verbose
+  -log-format string   format of the log messages written to standard error, "text" or "json" (default "text")
+  -dry-run             print the files that would be written and their diff instead of writing them
+  -stdout              print the generated files to standard output instead of writing them
+  -interval duration   time between two scans in watch mode (default 500ms)
+  -debounce duration   time without changes before regenerating in watch mode (default 300ms)
+  -force               overwrite an existing configuration file in init and output files
+                       that were not generated by sql2interface
+`
+
+// stringList is a flag value that collects every occurrence of a repeated flag.
+type stringList []string
+
+func (list *stringList) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
+// options contains the flags shared by all commands.
+type options struct {
+	configPath string
+	input      string
+	schema     string
+	outputDir  string
+	targets    string
+	overrides  stringList
+	quiet      bool
+	verbose    bool
+	debug      bool
+	logFormat  string
+	dryRun     bool
+	stdout     bool
+	interval   time.Duration
+	debounce   time.Duration
+	force      bool
+	profile    string
+	logger     *slog.Logger
+	// out receives the output of the commands, e.g. the files printed by -stdout, errOut the log messages and errors
+	out    io.Writer
+	errOut io.Writer
+}
+
+// register registers the shared flags on a flag set, including their short forms.
+func (opts *options) register(flags *flag.FlagSet) {
+	flags.StringVar(&opts.configPath, "config", "", "")
+	flags.StringVar(&opts.configPath, "c", "", "")
+	flags.StringVar(&opts.input, "input", "", "")
+	flags.StringVar(&opts.input, "i", "", "")
+	flags.StringVar(&opts.schema, "input-schema", "", "")
+	flags.StringVar(&opts.outputDir, "output", "", "")
+	flags.StringVar(&opts.outputDir, "o", "", "")
+	flags.StringVar(&opts.targets, "targets", "", "")
+	flags.Var(&opts.overrides, "set", "")
+	flags.BoolVar(&opts.quiet, "quiet", false, "")
+	flags.BoolVar(&opts.quiet, "q", false, "")
+	flags.BoolVar(&opts.verbose, "verbose", false, "")
+	flags.BoolVar(&opts.verbose, "v", false, "")
+	flags.BoolVar(&opts.debug, "debug", false, "")
+	flags.StringVar(&opts.logFormat, "log-format", "text", "")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "")
+	flags.BoolVar(&opts.stdout, "stdout", false, "")
+	flags.DurationVar(&opts.interval, "interval", 500*time.Millisecond, "")
+	flags.DurationVar(&opts.debounce, "debounce", 300*time.Millisecond, "")
+	flags.BoolVar(&opts.force, "force", false, "")
+	flags.StringVar(&opts.profile, "profile", src.EnvProfile(), "")
+	flags.StringVar(&opts.profile, "p", src.EnvProfile(), "")
+}
+
+// logLevel returns the name of the log level selected by the flags, see src.LogLevels.
+func (opts *options) logLevel() string {
+	switch {
+	case opts.quiet:
+		return "quiet"
+	case opts.debug:
+		return "debug"
+	case opts.verbose:
+		return "verbose"
+	default:
+		return "normal"
+	}
+}
+
+// printError prints an error to standard error. Errors containing more than one problem are printed with one line per problem.
+// With -log-format json, every problem is logged as a message of the level error instead.
+func (opts *options) printError(err error) {
+	for _, line := range strings.Split(err.Error(), "\n") {
+		if opts.logger != nil && opts.logFormat == "json" {
+			opts.logger.Error(line)
+			continue
+		}
+		fmt.Fprintln(opts.errOut, "x> "+line)
+	}
+}
+
+// newSQL2Interface creates a SQL2Interface instance for a loaded configuration that logs with the logger of the flags.
+func (opts *options) newSQL2Interface(conf *src.Config) *src.SQL2Interface {
+	s2i := src.NewSQL2InterfaceFromConfig(conf)
+	s2i.Logger = opts.logger
+	s2i.Force = opts.force
+	return s2i
+}
+
+// runCLI parses the command line arguments, runs the selected command and returns the exit code.
+// The output of the command is written to stdout, log messages and errors are written to stderr.
+func runCLI(args []string, stdout io.Writer, stderr io.Writer) int {
+	command := "generate"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command = args[0]
+		args = args[1:]
+	}
+
+	var run func(opts *options) error
+
+	switch command {
+	case "generate":
+		run = runGenerate
+	case "check":
+		run = runCheck
+	case "init":
+		run = runInit
+	case "inspect":
+		run = runInspect
+	case "dump":
+		run = runDump
+	case "watch":
+		run = runWatch
+	case "version":
+		fmt.Fprintln(stdout, "sql2interface "+buildVersion())
+		return 0
+	case "help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command '%v'\n\n%v", command, usage)
+		return 2
+	}
+
+	opts := &options{out: stdout, errOut: stderr}
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	opts.register(flags)
+
+	if parseError := flags.Parse(args); parseError != nil {
+		if errors.Is(parseError, flag.ErrHelp) {
+			fmt.Fprint(stdout, usage)
+			return 0
+		}
+		fmt.Fprintf(stderr, "%v\n\n%v", parseError, usage)
+		return 2
+	}
+
+	if flags.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments: %v\n\n%v", strings.Join(flags.Args(), " "), usage)
+		return 2
+	}
+
+	if opts.configPath == "" {
+		opts.configPath = src.FindConfigFile(".")
+	}
+
+	// log messages are written to standard error, so standard output stays clean for -stdout
+	logger, loggerError := src.NewLogger(stderr, opts.logLevel(), opts.logFormat)
+	if loggerError != nil {
+		fmt.Fprintf(stderr, "%v\n\n%v", loggerError, usage)
+		return 2
+	}
+	opts.logger = logger
+
+	if runError := run(opts); runError != nil {
+		opts.printError(runError)
+		return 1
+	}
+
+	return 0
+}
+
+// buildVersion returns the version set at build time or the module version if the binary was installed with go install.
+func buildVersion() string {
+	if version != "dev" {
+		return version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	return version
+}
+
+// loadConfig loads the configuration file, applies all overrides from the command line and validates the result.
+func loadConfig(opts *options) (*src.Config, error) {
+	opts.logger.Debug("loading configuration", "path", opts.configPath, "profile", opts.profile)
+	for _, name := range src.IgnoredEnvVariables(os.Environ()) {
+		opts.logger.Debug("environment variable is not a configuration key, ignoring it", "variable", name)
+	}
+
+	return src.LoadAndValidateConfig(opts.configPath, opts.profile, func(conf *src.Config) error {
+		if overrideError := src.ApplyConfigOverrides(conf, opts.overrides); overrideError != nil {
+			return overrideError
+		}
+
+		if opts.input != "" {
+			conf.Input = opts.input
+		}
+
+		if opts.schema != "" {
+			conf.InputSchema = opts.schema
+		}
+
+		if opts.outputDir != "" {
+			conf.Output.SetOutputDir(opts.outputDir)
+		}
+
+		if opts.targets != "" {
+			enabled := make(map[string]bool)
+			for _, target := range strings.Split(opts.targets, ",") {
+				target = strings.TrimSpace(target)
+				if conf.Output.OutputDir(target) == "" && conf.Output.OutputFile(target) == "" {
+					return fmt.Errorf("target '%v' is not configured in output", target)
+				}
+				enabled[target] = true
+			}
+
+			for _, target := range conf.Output.Targets() {
+				if !enabled[target] {
+					conf.Output.Disable(target)
+				}
+			}
+		}
+
+		opts.logger.Debug("configuration loaded", "input", conf.Input, "targets", strings.Join(conf.Output.Targets(), ", "))
+		return nil
+	})
+}
+
+// runGenerate converts the sql files and writes the output files.
+// With -stdout the generated files are printed instead, with -dry-run the files that would change are listed together with their diff.
+func runGenerate(opts *options) error {
+	conf, loadError := loadConfig(opts)
+	if loadError != nil {
+		return loadError
+	}
+
+	s2i := opts.newSQL2Interface(conf)
+
+	if !opts.dryRun && !opts.stdout {
+		return s2i.Run()
+	}
+
+	// files that could be converted are still printed, the errors are returned afterwards
+	files, generateError := s2i.GenerateInput()
+
+	for _, file := range files {
+		if opts.stdout {
+			fmt.Fprint(opts.out, file.Content)
+			if !strings.HasSuffix(file.Content, "\n") {
+				fmt.Fprintln(opts.out)
+			}
+			continue
+		}
+
+		existing, readError := os.ReadFile(file.Path())
+		switch {
+		case readError != nil:
+			fmt.Fprintf(opts.out, "would create %v\n", file.Path())
+		case string(existing) == file.Content:
+			fmt.Fprintf(opts.out, "unchanged %v\n", file.Path())
+			continue
+		case !opts.force && !src.IsGeneratedFile(existing):
+			fmt.Fprintf(opts.out, "would not overwrite %v, it was not generated by sql2interface\n", file.Path())
+			continue
+		default:
+			fmt.Fprintf(opts.out, "would update %v\n", file.Path())
+		}
+
+		fmt.Fprint(opts.out, src.UnifiedDiff(file.Path(), file.Path()+" (generated)", string(existing), file.Content))
+	}
+
+	return generateError
+}
+
+// runCheck generates all output files in memory and fails with a unified diff if any file on disk is out of date.
+func runCheck(opts *options) error {
+	conf, loadError := loadConfig(opts)
+	if loadError != nil {
+		return loadError
+	}
+
+	diffs, checkError := opts.newSQL2Interface(conf).Check()
+	if checkError != nil {
+		return checkError
+	}
+
+	if len(diffs) > 0 {
+		fmt.Fprint(opts.out, strings.Join(diffs, ""))
+		return fmt.Errorf("%v generated file(s) are out of date, run s2i generate and remove orphaned files", len(diffs))
+	}
+
+	opts.logger.Info("generated files are up to date")
+	return nil
+}
+
+// runInit writes a commented configuration file listing every supported option.
+// If an input directory is given with -i, its sql files are scanned to prefill the input, list the tables and suggest ignore_columns.
+func runInit(opts *options) error {
+	if format := src.ConfigFormat(opts.configPath); format != src.FormatYAML {
+		return fmt.Errorf("init writes YAML configuration files, %v cannot be created as %v", opts.configPath, format)
+	}
+
+	if _, statError := os.Stat(opts.configPath); statError == nil && !opts.force {
+		return fmt.Errorf("%v already exists, use -force to overwrite it", opts.configPath)
+	}
+
+	input := "./sql"
+	var tables []src.ScannedTable
+
+	if opts.input != "" {
+		input = relativeToConfig(opts.configPath, opts.input)
+
+		scanned, scanError := src.ScanTables(opts.input, src.InputOptions{Logger: opts.logger})
+		if scanError != nil {
+			return scanError
+		}
+		tables = scanned
+		opts.logger.Info("scanned input directory", "input", opts.input, "tables", len(tables))
+	}
+
+	if writeError := os.WriteFile(opts.configPath, []byte(src.ScaffoldConfig(input, tables)), 0644); writeError != nil {
+		return writeError
+	}
+
+	opts.logger.Info("wrote configuration", "path", opts.configPath)
+	return nil
+}
+
+// relativeToConfig converts a path given on the command line into a path relative to the directory of the configuration file,
+// because relative paths in the configuration are resolved against that directory.
+func relativeToConfig(configPath string, path string) string {
+	absoluteConfigDir, configError := filepath.Abs(filepath.Dir(configPath))
+	absolutePath, pathError := filepath.Abs(path)
+	if configError != nil || pathError != nil {
+		return path
+	}
+
+	relativePath, relativeError := filepath.Rel(absoluteConfigDir, absolutePath)
+	if relativeError != nil {
+		return path
+	}
+
+	relativePath = filepath.ToSlash(relativePath)
+	if strings.HasPrefix(relativePath, "..") {
+		return relativePath
+	}
+	return "./" + relativePath
+}
+
+// runInspect prints the effective configuration after all overrides.
+func runInspect(opts *options) error {
+	conf, loadError := loadConfig(opts)
+	if loadError != nil {
+		return loadError
+	}
+
+	content, marshalError := yaml.Marshal(conf)
+	if marshalError != nil {
+		return marshalError
+	}
+
+	fmt.Fprint(opts.out, string(content))
+	return nil
+}
+
+// runDump prints the parsed tables of the input and the combined structures as JSON.
+// Files that could be parsed are still printed, the errors are returned afterwards.
+func runDump(opts *options) error {
+	conf, loadError := loadConfig(opts)
+	if loadError != nil {
+		return loadError
+	}
+
+	schema, schemaError := opts.newSQL2Interface(conf).SchemaInput()
+	if _, isConfigError := schemaError.(src.ConfigError); isConfigError {
+		return schemaError
+	}
+
+	if writeError := src.WriteSchema(opts.out, schema); writeError != nil {
+		return writeError
+	}
+
+	return schemaError
+}
+
+// watchedInput returns the path whose changes trigger a regeneration: the input schema if it is set, otherwise the input directory.
+func watchedInput(conf *src.Config) string {
+	return src.FirstNonEmpty(conf.InputSchema, conf.Input)
+}
+
+// watchedPaths returns the paths watched in watch mode: the input, the configuration file and the overlay file of the profile.
+// The overlay file is watched even if it does not exist yet, so creating it is detected as well.
+func watchedPaths(opts *options, conf *src.Config) []string {
+	paths := []string{watchedInput(conf), opts.configPath}
+	if opts.profile != "" {
+		paths = append(paths, src.ProfileOverlayPath(opts.configPath, opts.profile))
+	}
+	return paths
+}
+
+// isConfigFile checks if a changed path is the configuration file or the overlay file of the profile.
+func isConfigFile(opts *options, path string) bool {
+	if filepath.Clean(path) == filepath.Clean(opts.configPath) {
+		return true
+	}
+	return opts.profile != "" && filepath.Clean(path) == filepath.Clean(src.ProfileOverlayPath(opts.configPath, opts.profile))
+}
+
+// runWatch generates the output files and regenerates them whenever a sql file or the configuration file changes.
+// Bursts of changes are debounced. Only changed sql files are parsed again, a changed configuration is reloaded completely.
+func runWatch(opts *options) error {
+	conf, loadError := loadConfig(opts)
+	if loadError != nil {
+		return loadError
+	}
+
+	s2i := opts.newSQL2Interface(conf)
+	// errors are printed without stopping the watcher, so they can be fixed while watching
+	regenerate := func() int {
+		files, generateError := s2i.GenerateInput()
+		written, writeError := s2i.WriteFiles(files)
+
+		if regenerateError := errors.Join(generateError, writeError); regenerateError != nil {
+			opts.printError(regenerateError)
+		}
+		return written
+	}
+
+	regenerate()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	watcher := src.NewWatcher(opts.interval, opts.debounce, watchedPaths(opts, conf)...)
+	watcher.Filter = conf.InputFilter()
+	// the files excluded by the filter are dropped from the first snapshot
+	watcher.Changes()
+	opts.logger.Info("watching for changes, press ctrl+c to stop", "input", watchedInput(conf), "config", opts.configPath)
+
+	watcher.Watch(ctx, func(changed []string) {
+		started := time.Now()
+
+		for _, path := range changed {
+			if !isConfigFile(opts, path) {
+				continue
+			}
+
+			reloaded, reloadError := loadConfig(opts)
+			if reloadError != nil {
+				opts.printError(fmt.Errorf("error reloading configuration: %w", reloadError))
+				return
+			}
+
+			s2i = opts.newSQL2Interface(reloaded)
+			// the input, its extensions and ignore_files may have changed, files that are watched from now on are not reported as changes
+			watcher.Paths = watchedPaths(opts, reloaded)
+			watcher.Filter = reloaded.InputFilter()
+			watcher.Changes()
+			conf = reloaded
+			break
+		}
+
+		var names []string
+		for _, path := range changed {
+			names = append(names, filepath.Base(path))
+		}
+
+		written := regenerate()
+		opts.logger.Info("regenerated", "changed", strings.Join(names, ", "), "written", written, "duration", time.Since(started).Round(time.Millisecond))
+	})
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MathiasMantai/sql2interface/src"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeProject creates a configuration file converting a single table into a Go file and returns its path.
+func writeProject(t *testing.T) string {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sql"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sql", "users.sql"), []byte("CREATE TABLE users (id INT, name TEXT)"), 0644))
+
+	configPath := filepath.Join(dir, "s2iconfig.yaml")
+	config := "input: ./sql\noutput:\n  go:\n    output_dir: ./models\n    output_file: types.go\n    package_name: models\n"
+	assert.NoError(t, os.WriteFile(configPath, []byte(config), 0644))
+
+	return configPath
+}
+
+func TestRunCLI(t *testing.T) {
+	configPath := writeProject(t)
+	output := filepath.Join(filepath.Dir(configPath), "models", "types.go")
+
+	tests := []struct {
+		name     string
+		args     []string
+		exitCode int
+		stdout   string
+		stderr   string
+		written  bool
+	}{
+		{name: "version", args: []string{"version"}, stdout: "sql2interface "},
+		{name: "help", args: []string{"help"}, stdout: "Usage: s2i"},
+		{name: "help flag", args: []string{"check", "-h"}, stdout: "Usage: s2i"},
+		{name: "generate", args: []string{"generate", "-config", configPath}, written: true},
+		{name: "default to generate", args: []string{"-config", configPath}, written: true},
+		{name: "short flags", args: []string{"-c", configPath, "-q", "-o", filepath.Join(filepath.Dir(configPath), "models")}, written: true},
+		{name: "check stale", args: []string{"check", "-c", configPath}, exitCode: 1, stdout: "+type Users struct {", stderr: "x> 1 generated file(s) are out of date"},
+		{name: "inspect", args: []string{"inspect", "-c", configPath}, stdout: "package_name: models"},
+		{name: "unknown command", args: []string{"convert"}, exitCode: 2, stderr: "unknown command 'convert'"},
+		{name: "unknown flag", args: []string{"generate", "-unknown"}, exitCode: 2, stderr: "flag provided but not defined: -unknown"},
+		{name: "unexpected argument", args: []string{"generate", "-c", configPath, "extra"}, exitCode: 2, stderr: "unexpected arguments: extra"},
+		{name: "missing configuration", args: []string{"-c", filepath.Join(t.TempDir(), "missing.yaml")}, exitCode: 1, stderr: "x> "},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Remove(output)
+			var stdout, stderr bytes.Buffer
+
+			assert.Equal(t, test.exitCode, runCLI(test.args, &stdout, &stderr), stderr.String())
+			assert.Contains(t, stdout.String(), test.stdout)
+			assert.Contains(t, stderr.String(), test.stderr)
+
+			_, statError := os.Stat(output)
+			assert.Equal(t, test.written, statError == nil, "output file written")
+		})
+	}
+}
+
+func TestRunGenerateDryRun(t *testing.T) {
+	configPath := writeProject(t)
+	models := filepath.Join(filepath.Dir(configPath), "models")
+	// a second target whose file exists already, so it would be updated
+	config := "input: ./sql\noutput:\n  go:\n    output_dir: ./models\n    output_file: types.go\n    package_name: models\n  typescript:\n    output_dir: ./models\n    output_file: types.ts\n"
+	assert.NoError(t, os.WriteFile(configPath, []byte(config), 0644))
+	assert.NoError(t, os.MkdirAll(models, 0755))
+	stale := "// Code generated by sql2interface. DO NOT EDIT.\n\ninterface Users {}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(models, "types.ts"), []byte(stale), 0644))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 0, runCLI([]string{"generate", "-c", configPath, "-dry-run"}, &stdout, &stderr), stderr.String())
+
+	assert.Contains(t, stdout.String(), "would create "+filepath.Join(models, "types.go")+"\n")
+	assert.Contains(t, stdout.String(), "would update "+filepath.Join(models, "types.ts")+"\n")
+	assert.Contains(t, stdout.String(), "-interface Users {}")
+
+	// nothing is written
+	_, statError := os.Stat(filepath.Join(models, "types.go"))
+	assert.True(t, os.IsNotExist(statError))
+	content, _ := os.ReadFile(filepath.Join(models, "types.ts"))
+	assert.Equal(t, stale, string(content))
+}
+
+func TestRunGenerateStdout(t *testing.T) {
+	configPath := writeProject(t)
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 0, runCLI([]string{"generate", "-c", configPath, "-stdout", "-v"}, &stdout, &stderr), stderr.String())
+
+	// only the code is written to standard output, the log messages are written to standard error
+	assert.True(t, strings.HasPrefix(stdout.String(), "// Code generated by sql2interface. DO NOT EDIT."), stdout.String())
+	assert.Contains(t, stdout.String(), "type Users struct {")
+	assert.NotContains(t, stdout.String(), "level=")
+	assert.Contains(t, stderr.String(), "converting file")
+
+	_, statError := os.Stat(filepath.Join(filepath.Dir(configPath), "models"))
+	assert.True(t, os.IsNotExist(statError), "no files are written")
+}
+
+func TestWatchedPaths(t *testing.T) {
+	opts := &options{configPath: filepath.Join("config", "s2iconfig.yaml"), profile: "dev"}
+
+	paths := watchedPaths(opts, &src.Config{Input: "sql", InputSchema: "schema.json"})
+	assert.Equal(t, []string{"schema.json", filepath.Join("config", "s2iconfig.yaml"), filepath.Join("config", "s2iconfig.dev.yaml")}, paths)
+	assert.True(t, isConfigFile(opts, filepath.Join("config", "s2iconfig.dev.yaml")))
+	assert.False(t, isConfigFile(opts, "schema.json"))
+
+	opts.profile = ""
+	assert.Equal(t, []string{"sql", filepath.Join("config", "s2iconfig.yaml")}, watchedPaths(opts, &src.Config{Input: "sql"}))
+}
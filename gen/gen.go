@@ -0,0 +1,254 @@
+// Package gen converts the tables of the package schema into TypeScript interfaces and Go structs.
+//
+// Every call of Render is independent: the options are not modified and no state is kept between calls,
+// so a single Options value can be shared between goroutines.
+//
+// This package follows semantic versioning, unlike the package src used by the command line tool.
+package gen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MathiasMantai/sql2interface/schema"
+	"github.com/MathiasMantai/sql2interface/src"
+)
+
+// Target is an output type of the generated code.
+type Target string
+
+const (
+	// TypeScript generates TypeScript interfaces
+	TypeScript Target = "typescript"
+	// Go generates Go structs
+	Go Target = "go"
+)
+
+// Targets returns every supported output type.
+func Targets() []Target {
+	return []Target{TypeScript, Go}
+}
+
+// Case is the naming strategy of generated names.
+// The zero value keeps the name as written in the DDL with an upper case first letter, e.g. "Order_items".
+// Singularize and Initialisms require one of the other strategies.
+type Case string
+
+const (
+	// Pascal writes names like "OrderItems"
+	Pascal Case = "pascal"
+	// Camel writes names like "orderItems"
+	Camel Case = "camel"
+	// Snake writes names like "order_items"
+	Snake Case = "snake"
+	// Original keeps the name as written in the DDL, only supported for JSON tags
+	Original Case = "original"
+)
+
+// CollisionPolicy is the handling of columns that exist in more than one table of a combined structure.
+type CollisionPolicy string
+
+const (
+	// CollisionError fails the combined structure
+	CollisionError CollisionPolicy = src.CollisionError
+	// CollisionKeepFirst keeps the column of the first table
+	CollisionKeepFirst CollisionPolicy = src.CollisionKeepFirst
+	// CollisionKeepLast keeps the column of the last table
+	CollisionKeepLast CollisionPolicy = src.CollisionKeepLast
+	// CollisionPrefix keeps every column and prefixes it with the name of its table
+	CollisionPrefix CollisionPolicy = src.CollisionPrefix
+)
+
+// Options controls the generated code. The zero value generates every table with the default naming.
+// Rules are matched against table names and file names, which can be glob patterns, e.g. "audit_*".
+type Options struct {
+	// PackageName is the package of Go files. If it is empty, no package clause is written.
+	PackageName string
+	// ExportTypes adds an export statement for every TypeScript interface
+	ExportTypes bool
+	// IgnoreColumns are the columns that are not generated per table, file or pattern ("*" for every table)
+	IgnoreColumns map[string][]string
+	// Fields are additional fields per table, file, combined structure or pattern
+	Fields map[string][]Field
+	// Combine are structures combining the columns of several tables
+	Combine []Combine
+	// Naming is the naming of types, fields and JSON tags per output type
+	Naming map[Target]Naming
+}
+
+// Field is an additional field that is not a column of the table.
+type Field struct {
+	Name           string
+	GoType         string
+	TypeScriptType string
+	// Position is "start", "end" (the default) or "after:<column>"
+	Position string
+	Optional bool
+	// Comment is written above the field
+	Comment string
+	// Tags are the struct tags of the field in Go
+	Tags map[string]string
+}
+
+// Combine is a structure combining the columns of several tables.
+type Combine struct {
+	Name string
+	// Tables are the names, file names or patterns of the combined tables
+	Tables []string
+	// KeepTables also generates the combined tables on their own
+	KeepTables  bool
+	OnCollision CollisionPolicy
+}
+
+// Naming is the naming of generated types, fields and JSON tags.
+type Naming struct {
+	Types    Case
+	Fields   Case
+	JSONTags Case
+	// Initialisms writes common initialisms in upper case, e.g. UserID instead of UserId
+	Initialisms      bool
+	ExtraInitialisms []string
+	// Singularize singularizes type names, e.g. users becomes User
+	Singularize bool
+	TypePrefix  string
+	TypeSuffix  string
+	FieldPrefix string
+	FieldSuffix string
+	// Rename sets the names of single types and fields, e.g. "users.pw_hash": "PasswordHash"
+	Rename map[string]string
+}
+
+// CombineError is a combined structure that could not be generated.
+type CombineError struct {
+	Structure string
+	Target    Target
+	Err       error
+}
+
+func (combineError CombineError) Error() string {
+	return src.CombineError{Structure: combineError.Structure, Target: string(combineError.Target), Err: combineError.Err}.Error()
+}
+
+func (combineError CombineError) Unwrap() error {
+	return combineError.Err
+}
+
+// Render generates the code of a single file containing every table and combined structure for an output type.
+//
+// Parameters:
+// - tables: The tables to be generated, see schema.ParseDDL.
+// - target: The output type.
+// - opts: The options of the generated code.
+//
+// Return:
+// - []byte: The generated code. Combined structures that could not be generated are skipped.
+// - error: An error if the target is unknown, or a CombineError for every combined structure that could not be generated, joined with errors.Join.
+func Render(tables []schema.Table, target Target, opts Options) ([]byte, error) {
+	if target != TypeScript && target != Go {
+		return nil, fmt.Errorf("unknown target '%v', expected %v or %v", target, TypeScript, Go)
+	}
+
+	content, renderError := src.Render(toSQL(tables), string(target), opts.config())
+	return content, convertErrors(renderError)
+}
+
+// config converts the options into a configuration of the package src.
+func (opts Options) config() *src.Config {
+	conf := &src.Config{
+		IgnoreColumns: opts.IgnoreColumns,
+		Output: src.OutputConfig{
+			TypeScript: &src.TypeScriptOutput{ExportTypes: opts.ExportTypes},
+			Go:         &src.GoOutput{PackageName: opts.PackageName},
+		},
+	}
+
+	if len(opts.Fields) > 0 {
+		conf.ArbitraryFields = make(map[string]map[string]src.ArbitraryField)
+		for table, fields := range opts.Fields {
+			conf.ArbitraryFields[table] = make(map[string]src.ArbitraryField)
+			for i, field := range fields {
+				// arbitrary fields are added in the order of their keys
+				conf.ArbitraryFields[table][fmt.Sprintf("%06d", i)] = src.ArbitraryField{
+					Name:     field.Name,
+					TypeGo:   field.GoType,
+					TypeTs:   field.TypeScriptType,
+					Position: field.Position,
+					Tags:     field.Tags,
+					Optional: field.Optional,
+					Comment:  field.Comment,
+				}
+			}
+		}
+	}
+
+	if len(opts.Combine) > 0 {
+		conf.CombineTables = make(map[string]src.TableCombine)
+		for i, combine := range opts.Combine {
+			// combined structures are generated in the order of their keys
+			conf.CombineTables[fmt.Sprintf("%06d", i)] = src.TableCombine{
+				Name:                combine.Name,
+				Tables:              combine.Tables,
+				ConvertSingleTables: combine.KeepTables,
+				OnCollision:         string(combine.OnCollision),
+			}
+		}
+	}
+
+	if len(opts.Naming) > 0 {
+		conf.Naming = make(map[string]src.NamingConfig)
+		for target, naming := range opts.Naming {
+			conf.Naming[string(target)] = src.NamingConfig{
+				Types:            string(naming.Types),
+				Fields:           string(naming.Fields),
+				JSONTags:         string(naming.JSONTags),
+				Initialisms:      naming.Initialisms,
+				ExtraInitialisms: naming.ExtraInitialisms,
+				Singularize:      naming.Singularize,
+				TypePrefix:       naming.TypePrefix,
+				TypeSuffix:       naming.TypeSuffix,
+				FieldPrefix:      naming.FieldPrefix,
+				FieldSuffix:      naming.FieldSuffix,
+				Rename:           naming.Rename,
+			}
+		}
+	}
+
+	return conf
+}
+
+// toSQL converts tables into the table definitions of the package src.
+func toSQL(tables []schema.Table) []src.SQL {
+	var converted []src.SQL
+	for _, table := range tables {
+		sql := src.SQL{FileName: table.File, TableName: table.Name, OriginalName: table.Name}
+		for _, column := range table.Columns {
+			sql.Columns = append(sql.Columns, src.Column{
+				Name:         column.Name,
+				OriginalName: column.Name,
+				Type:         column.Type,
+				Constraints:  column.Constraints,
+				Comment:      column.Comment,
+			})
+		}
+		converted = append(converted, sql)
+	}
+	return converted
+}
+
+// convertErrors replaces the CombineErrors of the package src by CombineErrors of this package.
+func convertErrors(err error) error {
+	var conversionErrors src.ConversionErrors
+	if !errors.As(err, &conversionErrors) {
+		return err
+	}
+
+	var converted []error
+	for _, conversionError := range conversionErrors {
+		var combineError src.CombineError
+		if errors.As(conversionError, &combineError) {
+			conversionError = CombineError{Structure: combineError.Structure, Target: Target(combineError.Target), Err: combineError.Err}
+		}
+		converted = append(converted, conversionError)
+	}
+	return errors.Join(converted...)
+}
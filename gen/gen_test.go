@@ -0,0 +1,42 @@
+package gen
+
+import (
+	"testing"
+
+	"github.com/MathiasMantai/sql2interface/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTables = []schema.Table{
+	{Name: "users", Columns: []schema.Column{{Name: "id", Type: "int"}, {Name: "created_at", Type: "timestamp"}}},
+	{Name: "orders", Columns: []schema.Column{{Name: "id", Type: "bigint"}, {Name: "user_id", Type: "int"}}},
+}
+
+func TestRenderGo(t *testing.T) {
+	content, err := Render(testTables, Go, Options{
+		PackageName:   "models",
+		IgnoreColumns: map[string][]string{"*": {"created_at"}},
+		Fields:        map[string][]Field{"users": {{Name: "Orders", GoType: "[]Order", Optional: true}}},
+		Naming:        map[Target]Naming{Go: {Types: Pascal, Fields: Pascal, Initialisms: true, Singularize: true, JSONTags: Snake}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "// Code generated by sql2interface. DO NOT EDIT.\n\npackage models\n\n\ntype User struct {\n\tID int `json:\"id\"`\r\n\tOrders []Order\r\n}\n\ntype Order struct {\n\tID int64 `json:\"id\"`\r\n\tUserID int `json:\"user_id\"`\r\n}", string(content))
+}
+
+func TestRenderCombine(t *testing.T) {
+	opts := Options{Combine: []Combine{{Name: "UserOrders", Tables: []string{"users", "orders"}}}}
+
+	_, err := Render(testTables, TypeScript, opts)
+	var combineError CombineError
+	assert.ErrorAs(t, err, &combineError)
+	assert.Equal(t, CombineError{Structure: "UserOrders", Target: TypeScript, Err: combineError.Err}, combineError)
+
+	opts.Combine[0].OnCollision = CollisionKeepFirst
+	content, err := Render(testTables, TypeScript, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "// Code generated by sql2interface. DO NOT EDIT.\n\n\ninterface UserOrders {\n\tId: Number, \r\n\tCreated_at: String, \r\n\tUser_id: Number\r\n}", string(content))
+
+	_, err = Render(testTables, Target("rust"), opts)
+	assert.EqualError(t, err, "unknown target 'rust', expected typescript or go")
+}
@@ -3,6 +3,7 @@ module github.com/MathiasMantai/sql2interface
 go 1.22.4
 
 require (
+	github.com/pelletier/go-toml/v2 v2.4.3
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/text v0.17.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -1,11 +1,9 @@
 package main
 
 import (
-	"github.com/MathiasMantai/sql2interface/src"
+	"os"
 )
 
 func main() {
-	confDir := "./s2iconfig.yaml"
-	sqlParser := src.NewSQL2Interface(confDir)
-	sqlParser.Run()
+	os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
 }
@@ -0,0 +1,148 @@
+// Package schema is the model of the tables converted by sql2interface and parses them from SQL DDL.
+//
+// The tables of this package do not depend on an output type: names are the names as written in the DDL
+// and column types are SQL types. Use the package gen to convert them into TypeScript interfaces or Go structs.
+//
+// This package follows semantic versioning, unlike the package src used by the command line tool.
+package schema
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/MathiasMantai/sql2interface/src"
+)
+
+// Table is a table created by a CREATE TABLE statement.
+type Table struct {
+	// Name is the name of the table in lower case, e.g. "order_items"
+	Name string
+	// File is the name of the file the table was parsed from, e.g. "billing/invoices.sql". It can be empty.
+	// The rules of gen.Options match tables by their name or file.
+	File    string
+	Columns []Column
+}
+
+// Column is a column of a table.
+type Column struct {
+	// Name is the name of the column in lower case, e.g. "created_at"
+	Name string
+	// Type is the SQL type of the column in lower case, e.g. "varchar"
+	Type string
+	// Constraints is the rest of the column definition after the type in lower case, e.g. "not null default 0"
+	Constraints string
+	// Comment is the "--" comment on the line of the column, it is written above the generated field
+	Comment string
+}
+
+// ParseOptions controls how a DDL is parsed.
+type ParseOptions struct {
+	// FileName is the file name of the parsed tables, see Table.File
+	FileName string
+}
+
+// FSOptions controls which files of a file system are parsed by ParseFS.
+type FSOptions struct {
+	// Extensions are the extensions of the parsed files, ".sql" if empty
+	Extensions []string
+}
+
+// ParseError is a table that could not be parsed.
+type ParseError struct {
+	File  string
+	Table string
+	Err   error
+}
+
+func (parseError ParseError) Error() string {
+	return src.ParseError(parseError).Error()
+}
+
+func (parseError ParseError) Unwrap() error {
+	return parseError.Err
+}
+
+// ParseDDL parses every CREATE TABLE statement of a DDL.
+// Statements are separated by semicolons outside of strings and comments, other statements (e.g. CREATE INDEX or INSERT) and comments are skipped.
+//
+// Parameters:
+// - r: The reader the DDL is read from.
+// - opts: The options of the DDL.
+//
+// Return:
+// - []Table: The tables in the order of the DDL. Tables that could not be parsed are skipped.
+// - error: An error if the DDL could not be read, or a ParseError for every statement that could not be split or parsed, joined with errors.Join.
+func ParseDDL(r io.Reader, opts ParseOptions) ([]Table, error) {
+	parsed, parseError := src.ParseDDL(r, src.DDLOptions{FileName: opts.FileName})
+	return fromSQL(parsed), convertErrors(parseError)
+}
+
+// ParseFS parses every file of a file system with one of the configured extensions, e.g. the files of an embed.FS.
+// Every file is parsed like a DDL by ParseDDL, the file name of the tables is the path of the file within fsys.
+// Hidden directories are skipped. Use fs.Sub to parse a subdirectory of the file system.
+//
+// Parameters:
+// - fsys: The file system containing the SQL files.
+// - opts: The extensions of the parsed files.
+//
+// Return:
+// - []Table: The tables sorted by file name. Tables that could not be parsed are skipped.
+// - error: An error if the file system could not be read, or a ParseError for every file or table that could not be read or parsed, joined with errors.Join.
+func ParseFS(fsys fs.FS, opts FSOptions) ([]Table, error) {
+	files, findError := src.FindInputFilesFS(fsys, src.InputOptions{Extensions: opts.Extensions})
+	if findError != nil {
+		return nil, findError
+	}
+
+	var tables []Table
+	var parseErrors []error
+
+	for _, file := range files {
+		content, readError := file.ReadFile()
+		if readError != nil {
+			parseErrors = append(parseErrors, ParseError{File: file.Name, Err: readError})
+			continue
+		}
+
+		parsed, parseError := ParseDDL(strings.NewReader(string(content)), ParseOptions{FileName: file.Name})
+		tables = append(tables, parsed...)
+		if parseError != nil {
+			parseErrors = append(parseErrors, parseError)
+		}
+	}
+
+	return tables, errors.Join(parseErrors...)
+}
+
+// fromSQL converts the table definitions of the package src into tables.
+func fromSQL(parsed []src.SQL) []Table {
+	var tables []Table
+	for _, sql := range parsed {
+		table := Table{Name: sql.OriginalName, File: sql.FileName}
+		for _, column := range sql.Columns {
+			table.Columns = append(table.Columns, Column{Name: column.OriginalName, Type: column.Type, Constraints: column.Constraints, Comment: column.Comment})
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// convertErrors replaces the ParseErrors of the package src by ParseErrors of this package.
+func convertErrors(err error) error {
+	var conversionErrors src.ConversionErrors
+	if !errors.As(err, &conversionErrors) {
+		return err
+	}
+
+	var converted []error
+	for _, conversionError := range conversionErrors {
+		var parseError src.ParseError
+		if errors.As(conversionError, &parseError) {
+			conversionError = ParseError(parseError)
+		}
+		converted = append(converted, conversionError)
+	}
+	return errors.Join(converted...)
+}
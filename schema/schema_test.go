@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDDL(t *testing.T) {
+	tables, err := ParseDDL(strings.NewReader("CREATE TABLE users (id INT, email TEXT);\nCREATE TABLE broken (id);"), ParseOptions{FileName: "schema.sql"})
+
+	assert.Equal(t, []Table{{Name: "users", File: "schema.sql", Columns: []Column{{Name: "id", Type: "int"}, {Name: "email", Type: "text"}}}}, tables)
+
+	var parseError ParseError
+	assert.ErrorAs(t, err, &parseError)
+	assert.Equal(t, "broken", parseError.Table)
+	assert.EqualError(t, err, "schema.sql: table broken: invalid column definition 'id', expected a column name and type")
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"users.sql":            {Data: []byte("CREATE TABLE users (id INT)")},
+		"billing/invoices.sql": {Data: []byte("CREATE TABLE invoices (id INT); CREATE TABLE payments (id INT)")},
+		"README.md":            {Data: []byte("# Schema")},
+	}
+
+	tables, err := ParseFS(fsys, FSOptions{})
+	assert.NoError(t, err)
+
+	var names []string
+	for _, table := range tables {
+		names = append(names, table.File+":"+table.Name)
+	}
+	assert.Equal(t, []string{"billing/invoices.sql:invoices", "billing/invoices.sql:payments", "users.sql:users"}, names)
+}
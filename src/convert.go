@@ -1,10 +1,18 @@
 package src
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -14,157 +22,410 @@ type SQL2Interface struct {
 	Config   *Config
 	Sql      SQL
 	Combiner map[string][]Combiner
+	// Logger receives the progress messages of the conversion, see NewLogger. If it is nil, nothing is logged.
+	Logger *slog.Logger
+	// Force overwrites existing output files that were not generated by sql2interface, see WriteFiles
+	Force      bool
+	parseCache map[string]cachedParse
+}
+
+// cachedParse is the result of parsing a file, reused as long as the file content does not change.
+type cachedParse struct {
+	Content string
+	Tables  []SQL
+	Error   error
 }
 
 type SQL struct {
-	FileName  string   `json:"file_name"`
-	TableName string   `json:"table_name"`
-	Columns   []Column `json:"columns"`
+	FileName     string   `json:"file_name"`
+	TableName    string   `json:"table_name"`
+	OriginalName string   `json:"original_name"`
+	Columns      []Column `json:"columns"`
 }
 
 type Column struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
+	Name         string            `json:"name"`
+	OriginalName string            `json:"original_name,omitempty"`
+	Type         string            `json:"type"`
+	Optional     bool              `json:"optional,omitempty"`
+	Comment      string            `json:"comment,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	// Constraints is the rest of the column definition after the type in lower case, e.g. "not null default 0"
+	Constraints string `json:"constraints,omitempty"`
+}
+
+// GeneratedFile is an output file created by the conversion together with its content.
+type GeneratedFile struct {
+	Target  string
+	Dir     string
+	Name    string
+	Content string
+}
+
+// Path returns the path of the generated file.
+func (file GeneratedFile) Path() string {
+	return filepath.Join(file.Dir, file.Name)
 }
 
 type ConvertedStructure struct {
 	StructureDefinition map[string]string
 	StructureNames      map[string][]string
+	// Structures contains every converted structure per output type, used to write one file per structure
+	Structures map[string][]Structure
 }
 
-// NewSQL2Interface initializes a new SQL2Interface instance with the provided configuration directory, source, and target.
-// It loads the configuration and combiner settings, and returns a pointer to the new instance.
+// Structure is a converted table or combined structure of an output type.
+type Structure struct {
+	// Name is the name of the interface or struct, e.g. "Users"
+	Name string
+	// Table is the name of the table, e.g. "users". For combined structures it is the name of the structure in snake case.
+	Table   string
+	Content string
+}
+
+// NewSQL2Interface initializes a new SQL2Interface instance with the provided configuration file.
+// It loads and validates the configuration with the profile selected by S2I_PROFILE, loads the combiner settings, and returns a pointer to the new instance.
 //
-// confDir: The directory path where the configuration file is located.
-// source: The source directory or file path for SQL files to be converted.
-// target: The target directory where the converted interface files will be saved.
+// confDir: The path of the configuration file.
 //
-// Returns: A pointer to a new SQL2Interface instance.
-func NewSQL2Interface(confDir string) *SQL2Interface {
-	s2i := &SQL2Interface{}
-	s2i.LoadConfig(confDir)
-	s2i.LoadCombiner()
-	return s2i
+// Returns:
+// - A pointer to a new SQL2Interface instance.
+// - An error if the configuration could not be loaded or is invalid. Problems in the configuration are returned as ConfigErrors.
+func NewSQL2Interface(confDir string) (*SQL2Interface, error) {
+	conf, loadError := LoadAndValidateConfig(confDir, EnvProfile(), nil)
+
+	if loadError != nil {
+		return nil, loadError
+	}
+
+	return NewSQL2InterfaceFromConfig(conf), nil
 }
 
 /* CONFIG */
 
-// LoadConfig initializes and loads the configuration from the provided directory path.
+// LoadConfig initializes and loads the configuration from the provided file path.
 //
 // Parameters:
-// confDir (string): The directory path where the configuration file is located.
+// confDir (string): The path of the configuration file.
 //
 // Returns:
-// This function does not return any value. However, it sets the Config field of the SQL2Interface instance to the loaded configuration.
-// If an error occurs during the loading process, it panics with the error message.
-func (s2i *SQL2Interface) LoadConfig(confDir string) {
+// An error if the configuration could not be loaded. On success, the Config field of the SQL2Interface instance is set to the loaded configuration.
+func (s2i *SQL2Interface) LoadConfig(confDir string) error {
 	// Load configuration from file
 	conf, loadConfError := LoadConfig(confDir)
 
 	if loadConfError != nil {
-		panic(loadConfError)
+		return loadConfError
 	}
 
 	s2i.Config = conf
+	return nil
+}
+
+// Generate processes the SQL files and converts them into interfaces or structs based on the configuration settings.
+// It checks if a file should be ignored, retrieves the file content, parses the SQL, adds the parsed data to the combiner,
+// and creates the content of the interface or struct files based on the configuration settings. Nothing is written to disk.
+// If mirror_input_dirs is set, the structures of every input subdirectory are written into the same subdirectory of the output directories,
+// combined structures are always written into the output directories themselves.
+//
+// Parameters:
+// files ([]InputFile): The SQL files within the input directory to be converted, see FindInputFiles.
+//
+// Return:
+// []GeneratedFile: The files that would be written for the configured outputs. Files and combined structures that could not be converted are skipped.
+// error: ConversionErrors containing a ParseError for every file and a CombineError for every combined structure that could not be converted, or nil.
+func (s2i *SQL2Interface) Generate(files []InputFile) ([]GeneratedFile, error) {
+	var conversionErrors ConversionErrors
+
+	tables, parseError := s2i.ParseFiles(files)
+	conversionErrors.Add(parseError)
+
+	generated, generateError := s2i.GenerateTables(tables)
+	conversionErrors.Add(generateError)
+
+	return generated, conversionErrors.Err()
 }
 
-// Convert processes a single SQL file and converts it into an interface or struct based on the configuration settings.
-// It checks if the file should be ignored, retrieves the file content, parses the SQL, adds the parsed data to the combiner,
-// and creates and saves the interface or struct files based on the configuration settings.
+// ParseFiles parses the SQL files that are not ignored by ignore_files into tables, see ParseStatements.
+// A file can contain several tables, every table keeps the name of its file to match the rules of the configuration.
 //
 // Parameters:
-// fileName (string): The name of the SQL file to be converted. If the source directory is a file, this parameter should be an empty string.
+// files ([]InputFile): The SQL files within the input directory to be parsed, see FindInputFiles.
 //
 // Return:
-// This function does not return any value. However, it prints error messages if any errors occur during the conversion process.
-func (s2i *SQL2Interface) Convert(files []fs.DirEntry) {
-	var output ConvertedStructure
-	output.StructureDefinition = make(map[string]string)
-	output.StructureNames = make(map[string][]string)
+// []SQL: The tables in the order of the files. Tables that could not be parsed are skipped.
+// error: ConversionErrors containing a ParseError for every file that could not be read and every statement that could not be parsed, or nil.
+func (s2i *SQL2Interface) ParseFiles(files []InputFile) ([]SQL, error) {
+	var tables []SQL
+	var conversionErrors ConversionErrors
 
 	for _, file := range files {
-		fileName := file.Name()
-		fmt.Printf("=> attempting to convert %v\n", fileName)
+		fileName := file.Name
 		ignoreFiles := s2i.Config.IgnoreFiles
-		sourceDir := s2i.Config.Input
 
-		if IsFileIgnored(fileName, ignoreFiles) {
+		if IsFileIgnored(s2i.logger(), fileName, ignoreFiles) {
 			continue
 		}
+		s2i.logger().Info("converting file", "file", fileName)
 
-		fileContent, getContentErr := GetFileContent(sourceDir, fileName)
+		rawContent, getContentErr := file.ReadFile()
 
 		if getContentErr != nil {
-			fmt.Println(getContentErr)
+			conversionErrors.Add(ParseError{File: fileName, Err: getContentErr})
 			continue
 		}
 
-		parsedDataTs, err := s2i.ParseSQL("typescript", fileName, fileContent)
-		s2i.AddArbitraryFields(&parsedDataTs, "typescript")
-		if err != nil {
-			fmt.Println("x> error parsing sql data for type 'typescript: " + err.Error())
-			continue
+		parsed, err := s2i.ParseStatementsCached(fileName, string(rawContent))
+		conversionErrors.Add(err)
+		if len(parsed) == 0 && err == nil {
+			s2i.logger().Warn("file does not contain a CREATE TABLE statement", "file", fileName)
 		}
+		tables = append(tables, parsed...)
+	}
+
+	return tables, conversionErrors.Err()
+}
+
+// GenerateTables converts parsed tables into the content of the output files without writing them, see ConvertTables.
+// The keep regions of existing output files are inserted into the generated files, see MergeKeepRegions.
+//
+// Parameters:
+// tables ([]SQL): The tables to be converted, see ParseFiles and LoadSchema.
+//
+// Return:
+// []GeneratedFile: The files that would be written for the configured outputs.
+// Combined structures that could not be converted and files with invalid keep regions are skipped.
+// error: ConversionErrors containing a CombineError for every combined structure that could not be converted
+// and a WriteError for every existing output file with invalid keep regions, or nil.
+func (s2i *SQL2Interface) GenerateTables(tables []SQL) ([]GeneratedFile, error) {
+	var generated []GeneratedFile
+	var conversionErrors ConversionErrors
+
+	outputs, convertError := s2i.ConvertTables(tables)
+	conversionErrors.Add(convertError)
+
+	for _, dir := range SortedKeys(outputs) {
+		generated = append(generated, s2i.OutputFiles(dir, *outputs[dir])...)
+	}
+
+	generated, keepError := s2i.preserveKeepRegions(generated)
+	conversionErrors.Add(keepError)
 
+	return generated, conversionErrors.Err()
+}
+
+// ConvertTables converts parsed tables (see ParseTable) into interfaces and structs for every output type.
+// It adds the arbitrary fields, collects the tables of combined structures and sanitizes the names of every structure.
+// If mirror_input_dirs is set, the structures are grouped by the subdirectory of the file they were parsed from,
+// combined structures are always part of the output directories themselves.
+//
+// Parameters:
+// tables ([]SQL): The tables to be converted.
+//
+// Return:
+// map[string]*ConvertedStructure: The converted structures per subdirectory, the key "" contains the structures of the output directories themselves.
+// error: ConversionErrors containing a CombineError for every combined structure that could not be converted, or nil.
+func (s2i *SQL2Interface) ConvertTables(tables []SQL) (map[string]*ConvertedStructure, error) {
+	outputs := map[string]*ConvertedStructure{"": NewConvertedStructure()}
+	s2i.ResetCombiner()
+
+	for _, table := range tables {
+		// tables are added to the combiner before their arbitrary fields, combined structures only get the arbitrary fields configured for them
+		parsedDataTs := s2i.ConvertTable("typescript", table)
 		addedToCombinerTs, indexTs := s2i.AddToCombiner("typescript", parsedDataTs)
+		s2i.AddArbitraryFields(&parsedDataTs, "typescript")
 
 		//convert to go struct
-		parsedDataGo, err := s2i.ParseSQL("go", fileName, fileContent)
-		s2i.AddArbitraryFields(&parsedDataGo, "go")
-		if err != nil {
-			fmt.Println(fmt.Errorf("x> error parsing sql data for type 'go': %v", err.Error()))
-			continue
-		}
-
+		parsedDataGo := s2i.ConvertTable("go", table)
 		addedToCombinerGo, indexGo := s2i.AddToCombiner("go", parsedDataGo)
+		s2i.AddArbitraryFields(&parsedDataGo, "go")
 
 		if addedToCombinerGo && indexGo != -1 && addedToCombinerTs && indexTs != -1 {
 			convertSingleTable := s2i.ConvertSingleTable("typescript", indexTs)
 
 			if !convertSingleTable {
-				fmt.Println("  => conversion will be skipped since convert_single_tables is set to false for this file...")
+				s2i.logger().Debug("skipping single table, convert_single_tables is false", "file", table.FileName)
 				continue
 			}
 		}
 
-		//add converted structures to outpui
-		output.StructureDefinition["typescript"] = output.StructureDefinition["typescript"] + "\n\n" + CreateInterface(parsedDataTs)
-		output.StructureNames["typescript"] = append(output.StructureNames["typescript"], parsedDataTs.TableName)
+		s2i.SanitizeStructure("typescript", &parsedDataTs)
+		s2i.SanitizeStructure("go", &parsedDataGo)
 
-		output.StructureDefinition["go"] = output.StructureDefinition["go"] + "\n\n" + CreateStruct(parsedDataGo)
-		output.StructureNames["go"] = append(output.StructureNames["go"], parsedDataGo.TableName)
+		outputDir := ""
+		if s2i.Config.MirrorInputDirs {
+			outputDir = InputFile{Name: table.FileName}.Dir()
+		}
+		if outputs[outputDir] == nil {
+			outputs[outputDir] = NewConvertedStructure()
+		}
+		output := outputs[outputDir]
 
+		//add converted structures to outpui
+		output.Add("typescript", parsedDataTs, CreateInterface(parsedDataTs))
+		output.Add("go", parsedDataGo, CreateStruct(parsedDataGo))
 	}
 
 	//handle conmbiners
-	s2i.CombinerToStructure(&output)
+	return outputs, s2i.CombinerToStructure(outputs[""])
+}
+
+// Convert converts the SQL files into interfaces or structs and writes them to the configured output files.
+// Files that could not be converted are skipped, the remaining output files are written anyway.
+//
+// Parameters:
+// files ([]InputFile): The SQL files within the input directory to be converted, see FindInputFiles.
+//
+// Return:
+// error: ConversionErrors containing every problem found while converting and writing, or nil.
+func (s2i *SQL2Interface) Convert(files []InputFile) error {
+	var conversionErrors ConversionErrors
+
+	generated, generateError := s2i.Generate(files)
+	conversionErrors.Add(generateError)
 
-	//get options for output
-	targetDirTs, tsDirExists := s2i.Config.Output["typescript"]["output_dir"]
-	targetFileNameTs, tsFileNameExists := s2i.Config.Output["typescript"]["output_file"]
-	exportTypesTs, tsExportTypesExists := s2i.Config.Output["typescript"]["export_types"]
+	_, writeError := s2i.WriteFiles(generated)
+	conversionErrors.Add(writeError)
 
-	targetDirGo, goDirExists := s2i.Config.Output["go"]["output_dir"]
-	goPackageName, packageNameExists := s2i.Config.Output["go"]["package_name"]
-	targetFileNameGo, goFileNameExists := s2i.Config.Output["go"]["output_file"]
+	return conversionErrors.Err()
+}
 
-	if tsDirExists && strings.TrimSpace(targetDirTs) != "" && tsFileNameExists && strings.TrimSpace(targetFileNameTs) != "" {
-		content := output.StructureDefinition["typescript"]
+// WriteFiles writes generated files to disk. Missing output directories are created and every file is replaced atomically (see SaveFile).
+// Files whose content did not change are not written, so their modification time stays the same.
+// Existing files without the generated code header are not overwritten unless Force is set, because they were probably written by hand.
+//
+// Parameters:
+// generated ([]GeneratedFile): The files to be written.
+//
+// Return:
+// int: The number of files that were written. Unchanged files and files that could not be written are skipped.
+// error: ConversionErrors containing a WriteError for every file that could not be written, or nil.
+func (s2i *SQL2Interface) WriteFiles(generated []GeneratedFile) (int, error) {
+	written := 0
+	var conversionErrors ConversionErrors
+
+	for _, file := range generated {
+		if existing, readError := os.ReadFile(file.Path()); readError == nil {
+			if string(existing) == file.Content {
+				s2i.logger().Debug("file unchanged", "path", file.Path())
+				continue
+			}
+			if !s2i.Force && !IsGeneratedFile(existing) {
+				conversionErrors.Add(WriteError{Path: file.Path(), Err: ErrNotGenerated})
+				continue
+			}
+		}
 
-		if exportTypesTs == "true" && tsExportTypesExists {
-			s2i.AddInterfaceExports(&content, output.StructureNames["typescript"])
+		// output directories and subdirectories of mirrored input directories are created on demand
+		if mkdirError := os.MkdirAll(file.Dir, 0755); mkdirError != nil {
+			conversionErrors.Add(WriteError{Path: file.Path(), Err: mkdirError})
+			continue
 		}
 
-		SaveFile(targetDirTs, targetFileNameTs, content)
+		if saveError := SaveFile(file.Dir, file.Name, file.Content); saveError != nil {
+			conversionErrors.Add(WriteError{Path: file.Path(), Err: saveError})
+			continue
+		}
+		s2i.logger().Info("wrote file", "path", file.Path())
+		written++
 	}
+	return written, conversionErrors.Err()
+}
+
+// Diff converts the SQL files in memory and compares the result with the output files on disk.
+// Output files that do not exist yet are compared against an empty file.
+//
+// Parameters:
+// files ([]InputFile): The SQL files within the input directory to be converted, see FindInputFiles.
+//
+// Return:
+// []string: A unified diff for every output file whose content on disk differs from the generated content.
+// error: The errors of Generate, see Generate.
+func (s2i *SQL2Interface) Diff(files []InputFile) ([]string, error) {
+	generated, generateError := s2i.Generate(files)
+	return DiffFiles(generated, s2i.Config.OutputDirs()...), generateError
+}
 
-	if goDirExists && strings.TrimSpace(targetDirGo) != "" && goFileNameExists && strings.TrimSpace(targetFileNameGo) != "" {
-		content := output.StructureDefinition["go"]
-		if goPackageName != "" && packageNameExists {
-			content = fmt.Sprintf("package %v\n%v", goPackageName, content)
+// DiffFiles compares generated files with the output files on disk.
+// Output files that do not exist yet are compared against an empty file.
+// Files written by sql2interface that are not generated anymore (see OrphanedFiles) are stale as well and compared with an empty generated file.
+//
+// Parameters:
+// generated ([]GeneratedFile): The generated files, see Generate.
+// outputDirs (...string): Further directories searched for orphaned files, e.g. the configured output directories.
+//
+// Return:
+// []string: A unified diff for every output file whose content on disk differs from the generated content and for every orphaned file.
+func DiffFiles(generated []GeneratedFile, outputDirs ...string) []string {
+	var diffs []string
+
+	for _, file := range generated {
+		existing, readError := os.ReadFile(file.Path())
+		if readError != nil {
+			existing = nil
 		}
-		SaveFile(targetDirGo, targetFileNameGo, content)
+
+		if diff := UnifiedDiff(file.Path(), file.Path()+" (generated)", string(existing), file.Content); diff != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	for _, orphaned := range OrphanedFiles(generated, outputDirs...) {
+		existing, _ := os.ReadFile(orphaned)
+		diffs = append(diffs, UnifiedDiff(orphaned, orphaned+" (generated)", string(existing), ""))
 	}
+
+	return diffs
+}
+
+// OrphanedFiles finds the files written by sql2interface (see IsGeneratedFile) that are not generated anymore,
+// e.g. the file of a dropped table or a file whose output_file was renamed.
+// The directories of the generated files and the given output directories are searched without their subdirectories,
+// only files with the extension of a generated file, .go or .ts are considered.
+//
+// Parameters:
+// generated ([]GeneratedFile): The generated files, see Generate.
+// outputDirs (...string): Further directories to be searched.
+//
+// Return:
+// []string: The paths of the orphaned files, sorted.
+func OrphanedFiles(generated []GeneratedFile, outputDirs ...string) []string {
+	paths := make(map[string]bool)
+	extensions := map[string]bool{".go": true, ".ts": true}
+	dirs := make(map[string]bool)
+
+	for _, file := range generated {
+		paths[filepath.Clean(file.Path())] = true
+		extensions[filepath.Ext(file.Name)] = true
+		dirs[filepath.Clean(file.Dir)] = true
+	}
+	for _, dir := range outputDirs {
+		if strings.TrimSpace(dir) != "" {
+			dirs[filepath.Clean(dir)] = true
+		}
+	}
+
+	var orphaned []string
+	for _, dir := range SortedKeys(dirs) {
+		entries, readError := os.ReadDir(dir)
+		if readError != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if !entry.Type().IsRegular() || paths[path] || !extensions[filepath.Ext(entry.Name())] {
+				continue
+			}
+
+			if content, readError := os.ReadFile(path); readError == nil && IsGeneratedFile(content) {
+				orphaned = append(orphaned, path)
+			}
+		}
+	}
+
+	sort.Strings(orphaned)
+	return orphaned
 }
 
 // AddInterfaceExports adds export statements for the given interface names to the content string.
@@ -179,94 +440,365 @@ func (s2i *SQL2Interface) AddInterfaceExports(content *string, interfaceNames []
 	*content = fmt.Sprintf("%v\n\nexport{\n%v\n}", *content, strings.Join(interfaceNames, ",\n"))
 }
 
-// ParseSQL parses a raw SQL string into a SQL struct and returns it along with any encountered error.
-// It extracts the table name and column details from the raw SQL string and populates the SQL struct accordingly.
+// ParseSQL parses a raw SQL string into a SQL struct for an output type and returns it along with any encountered error.
+// It parses the table with ParseTable and converts it with ConvertTable.
 //
 // Parameters:
+// - definitionType (string): The output type the SQL is parsed for.
 // - fileName (string): The name of the SQL file being parsed.
 // - rawSQL (string): The raw SQL string to be parsed.
 //
 // Return:
 // - SQL: A SQL struct containing the parsed table name and column details.
-// - error: An error encountered during the parsing process, or nil if no error occurred.
+// - error: A ParseError with the file and, if found, the table name, or nil if no error occurred.
 func (s2i *SQL2Interface) ParseSQL(definitionType string, fileName string, rawSQL string) (SQL, error) {
+	table, parseError := s2i.ParseTable(fileName, rawSQL)
+	if parseError != nil {
+		return SQL{}, parseError
+	}
+
+	return s2i.ConvertTable(definitionType, table), nil
+}
+
+// ParseTable parses a CREATE TABLE statement into a table definition that does not depend on an output type.
+// The table name and the column names are the names as written in the statement in lower case without their quotes, the column types are the SQL types, e.g. "varchar".
+// A "--" comment on the line of a column becomes the comment of the column. The configuration is not applied, see ConvertTable.
+//
+// Parameters:
+// - fileName (string): The name of the SQL file being parsed, used in errors and to match the rules of the configuration.
+// - rawSQL (string): The CREATE TABLE statement.
+//
+// Return:
+// - SQL: The table definition.
+// - error: A ParseError with the file and, if found, the table name, or nil if no error occurred.
+func (s2i *SQL2Interface) ParseTable(fileName string, rawSQL string) (SQL, error) {
 	var sql SQL
+	rawSQL, comments := extractComments(rawSQL)
+
+	if validateError := ValidateCreateStatement(rawSQL); validateError != nil {
+		return sql, ParseError{File: fileName, Err: validateError}
+	}
 
 	rawSQL = strings.ToUpper(rawSQL)
 
-	chunks := strings.Split(rawSQL, "(")
+	rawTableName, rawColumns, enclosed := enclosedColumns(rawSQL)
+	if rawColumns == "" && !enclosed {
+		return sql, ParseError{File: fileName, Table: s2i.ParseRawTableName(rawTableName), Err: errors.New("no column definitions found in CREATE statement")}
+	}
+	if !enclosed {
+		return sql, ParseError{File: fileName, Table: s2i.ParseRawTableName(rawTableName), Err: errors.New("column definitions are not closed in CREATE statement")}
+	}
 
-	rawTableName := strings.TrimSpace(chunks[0])
-	rawColumns := (strings.Split(chunks[1], ")"))[0]
-	columns, parseColumnsError := s2i.ParseRowColumnDefinitions(definitionType, fileName, rawColumns)
+	columns, parseColumnsError := s2i.ParseRowColumnDefinitions(rawColumns)
 
 	if parseColumnsError != nil {
-		return sql, parseColumnsError
+		return sql, ParseError{File: fileName, Table: s2i.ParseRawTableName(rawTableName), Err: parseColumnsError}
+	}
+	for i := range columns {
+		columns[i].Comment = comments[columns[i].OriginalName]
 	}
 	sql.FileName = fileName
-	sql.TableName = s2i.ParseRawTableName(rawTableName)
+	sql.OriginalName = s2i.ParseRawTableName(rawTableName)
+	sql.TableName = sql.OriginalName
 	sql.Columns = columns
 
 	return sql, nil
 }
 
-// ParseRawTableName extracts and processes the table name from a raw SQL table definition.
-// It removes SQL keywords like "CREATE", "TABLE", "IF", "NOT", and "EXISTS", and converts the remaining string to PascalCase.
+// extractComments removes the "--" and "/* */" comments from a CREATE TABLE statement.
+// A "--" comment belongs to the last column defined on its line, comments on lines without a column definition are dropped.
+//
+// Parameters:
+// - rawSQL (string): The CREATE TABLE statement.
+//
+// Return:
+// - string: The statement without comments.
+// - map[string]string: The comments by the column names in lower case.
+func extractComments(rawSQL string) (string, map[string]string) {
+	comments := make(map[string]string)
+	lineComments := make(map[int]string)
+	var code strings.Builder
+
+	// comments are only found outside of strings, e.g. not in DEFAULT '--'
+	segments, _ := scanSQL(rawSQL)
+	for _, segment := range segments {
+		switch segment.Kind {
+		case sqlLineComment:
+			lineComments[segment.Line] = strings.TrimPrefix(segment.Text, "--")
+		case sqlBlockComment:
+			code.WriteString(blankSQL(segment.Text))
+		default:
+			code.WriteString(segment.Text)
+		}
+	}
+
+	lines := strings.Split(code.String(), "\n")
+	for lineNumber, comment := range lineComments {
+		definitions := strings.Split(lines[lineNumber-1], ",")
+		for j := len(definitions) - 1; j >= 0; j-- {
+			definition := definitions[j]
+			// the first column can be defined on the line of the table name
+			if _, columns, opensTable := strings.Cut(definition, "("); opensTable && strings.Contains(strings.ToUpper(definition), "TABLE") {
+				definition = columns
+			}
+
+			if chunks := splitTopLevel(definition, unicode.IsSpace); len(chunks) > 1 {
+				if comment = strings.TrimSpace(comment); comment != "" {
+					comments[unquoteIdentifier(strings.ToLower(chunks[0]))] = comment
+				}
+				break
+			}
+		}
+	}
+
+	return code.String(), comments
+}
+
+// enclosedColumns splits a CREATE TABLE statement at the parentheses enclosing the column definitions.
+// Parentheses within the definitions, e.g. of VARCHAR(255), and within strings are balanced.
+//
+// Parameters:
+// - rawSQL (string): The CREATE TABLE statement without comments.
+//
+// Return:
+// - string: The statement in front of the opening parenthesis.
+// - string: The column definitions between the parentheses.
+// - bool: Whether the closing parenthesis was found.
+func enclosedColumns(rawSQL string) (string, string, bool) {
+	open := -1
+	depth := 0
+	var quote rune
+
+	for i, char := range rawSQL {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"' || char == '`':
+			quote = char
+		case char == '(':
+			if open < 0 {
+				open = i
+			}
+			depth++
+		case char == ')' && depth > 0:
+			depth--
+			if depth == 0 {
+				return strings.TrimSpace(rawSQL[:open]), rawSQL[open+1 : i], true
+			}
+		}
+	}
+
+	if open < 0 {
+		return strings.TrimSpace(rawSQL), "", false
+	}
+	return strings.TrimSpace(rawSQL[:open]), rawSQL[open+1:], false
+}
+
+// splitTopLevel splits a text at the separators that are neither enclosed in parentheses nor in quotes, e.g. the column definitions
+// of a CREATE TABLE statement at the commas that do not belong to DECIMAL(10,2). The parts are trimmed, empty parts are dropped.
+//
+// Parameters:
+// - text (string): The text to be split.
+// - isSeparator (func(rune) bool): Reports whether a character separates two parts.
+//
+// Return:
+// - []string: The parts of the text.
+func splitTopLevel(text string, isSeparator func(rune) bool) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	var quote rune
+
+	add := func(part string) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	for i, char := range text {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"' || char == '`':
+			quote = char
+		case char == '(':
+			depth++
+		case char == ')' && depth > 0:
+			depth--
+		case depth == 0 && isSeparator(char):
+			add(text[start:i])
+			start = i + utf8.RuneLen(char)
+		}
+	}
+	add(text[start:])
+
+	return parts
+}
+
+// ParseStatementsCached parses the content of a SQL file like ParseStatements but reuses the previous result if the content of the file did not change.
+// This keeps repeated conversions (e.g. in watch mode) from parsing files that were not modified.
+//
+// Parameters:
+// - fileName (string): The name of the SQL file being parsed.
+// - rawSQL (string): The content of the SQL file.
+//
+// Return:
+// - []SQL: Copies of the parsed tables that can be modified safely.
+// - error: The ConversionErrors of ParseStatements, or nil if no error occurred.
+func (s2i *SQL2Interface) ParseStatementsCached(fileName string, rawSQL string) ([]SQL, error) {
+	cached, exists := s2i.parseCache[fileName]
+
+	if exists && cached.Content == rawSQL {
+		s2i.logger().Log(context.Background(), LevelTrace, "reusing parsed file", "file", fileName)
+	} else {
+		tables, err := s2i.ParseStatements(fileName, rawSQL)
+		cached = cachedParse{Content: rawSQL, Tables: tables, Error: err}
+
+		if s2i.parseCache == nil {
+			s2i.parseCache = make(map[string]cachedParse)
+		}
+		s2i.parseCache[fileName] = cached
+	}
+
+	tables := make([]SQL, len(cached.Tables))
+	for i, sql := range cached.Tables {
+		sql.Columns = append([]Column(nil), sql.Columns...)
+		tables[i] = sql
+	}
+	return tables, cached.Error
+}
+
+// createTablePrefixPattern matches the keywords in front of the table name of a CREATE TABLE statement.
+var createTablePrefixPattern = regexp.MustCompile(`(?i)^\s*CREATE\s+TABLE(\s+IF\s+NOT\s+EXISTS)?(\s+|$)`)
+
+// ParseRawTableName extracts the table name from a raw SQL table definition.
+// It removes the leading keywords "CREATE TABLE" and "IF NOT EXISTS" and returns the remaining string in lower case,
+// names containing these words like "notifications" are kept.
+// The name of the generated structure is derived from it by the configured naming (see NamingConfig.TypeName).
 //
 // Parameters:
 // - rawTableDefinition (string): The raw SQL table definition string.
 //
 // Return:
-// - string: The processed table name in PascalCase.
+// - string: The table name as written in the SQL file, e.g. "order_items".
 func (s2i *SQL2Interface) ParseRawTableName(rawTableDefinition string) string {
-	replacer := strings.NewReplacer("CREATE", "", "TABLE", "", "IF", "", "NOT", "", "EXISTS", "")
-	caser := cases.Title(language.Und, cases.NoLower)
-	return caser.String(strings.ToLower(strings.TrimSpace(replacer.Replace(rawTableDefinition))))
+	return unquoteIdentifier(strings.ToLower(strings.TrimSpace(createTablePrefixPattern.ReplaceAllString(rawTableDefinition, ""))))
 }
 
+// unquoteIdentifier removes the backticks or double quotes around a quoted name, e.g. `order` or "first name".
+// Doubled quotes within the name become single quotes and qualified names like `shop`.`users` are unquoted part by part.
+//
+// Parameters:
+// - name (string): The name as written in the SQL file.
+//
+// Return:
+// - string: The name without quotes.
+func unquoteIdentifier(name string) string {
+	parts := splitTopLevel(name, func(char rune) bool { return char == '.' })
+
+	for i, part := range parts {
+		for _, quote := range []string{"`", `"`} {
+			if len(part) >= 2 && strings.HasPrefix(part, quote) && strings.HasSuffix(part, quote) {
+				parts[i] = strings.ReplaceAll(part[1:len(part)-1], quote+quote, quote)
+				break
+			}
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// tableConstraintPattern matches the definitions of constraints and indexes between the column definitions of a CREATE TABLE statement,
+// e.g. "PRIMARY KEY (id)" or "KEY idx_email (email)".
+var tableConstraintPattern = regexp.MustCompile("(?i)^(CONSTRAINT|PRIMARY\\s+KEY|FOREIGN\\s+KEY|UNIQUE|CHECK|EXCLUDE)\\b|^((FULLTEXT|SPATIAL)\\s+)?(KEY|INDEX)\\s*(\\(|[\\w`\"]+\\s*\\(\\s*[A-Z_`\"])")
+
 // ParseRowColumnDefinitions parses a raw SQL column definitions string into a slice of Column structs.
-// It extracts column names and types from the raw string, applies type mapping, and ignores specified columns.
+// It extracts the column names, SQL types and constraints in lower case from the raw string, the type mapping and naming are applied by ConvertTable.
 //
 // Parameters:
-// - fileName (string): The name of the SQL file being parsed. Used for logging and ignoring columns.
 // - rawColumnDefinitions (string): The raw SQL column definitions string to be parsed.
 //
 // Return:
 // - []Column: A slice of Column structs containing the parsed column names and types.
 // - error: An error encountered during the parsing process, or nil if no error occurred.
-func (s2i *SQL2Interface) ParseRowColumnDefinitions(definitionType string, fileName string, rawColumnDefinitions string) ([]Column, error) {
+func (s2i *SQL2Interface) ParseRowColumnDefinitions(rawColumnDefinitions string) ([]Column, error) {
 	var columns []Column
 
-	columnDefinitions := strings.Split(rawColumnDefinitions, ",")
+	columnDefinitions := splitTopLevel(rawColumnDefinitions, func(char rune) bool { return char == ',' })
 
 	for _, columnDefinition := range columnDefinitions {
 
-		if strings.TrimSpace(columnDefinition) == "" {
+		if tableConstraintPattern.MatchString(columnDefinition) {
 			continue
 		}
 
-		columnDefinition = strings.TrimSpace(columnDefinition)
-		chunks := strings.Split(columnDefinition, " ")
+		chunks := splitTopLevel(columnDefinition, unicode.IsSpace)
+		// the parameters of a type can be separated from it, e.g. DECIMAL (10, 2)
+		if len(chunks) > 2 && strings.HasPrefix(chunks[2], "(") {
+			chunks = append([]string{chunks[0], chunks[1] + chunks[2]}, chunks[3:]...)
+		}
+		if len(chunks) < 2 {
+			return nil, fmt.Errorf("invalid column definition '%v', expected a column name and type", strings.ToLower(columnDefinition))
+		}
+
+		originalName := unquoteIdentifier(strings.ToLower(chunks[0]))
+		columns = append(columns, Column{
+			Name:         originalName,
+			OriginalName: originalName,
+			Type:         strings.ToLower(chunks[1]),
+			Constraints:  strings.ToLower(strings.Join(chunks[2:], " ")),
+		})
+	}
+
+	return columns, nil
+}
+
+// ConvertTable converts a table definition created by ParseTable into a structure of an output type.
+// It applies the type mapping and naming of the output type and removes the ignored columns.
+//
+// Parameters:
+// - definitionType (string): The output type, "typescript" or "go".
+// - table (SQL): The table definition as written in the SQL file.
+//
+// Return:
+// - SQL: The structure of the output type.
+func (s2i *SQL2Interface) ConvertTable(definitionType string, table SQL) SQL {
+	naming := s2i.Naming(definitionType)
+	caser := cases.Title(language.Und, cases.NoLower)
+
+	sql := SQL{FileName: table.FileName, OriginalName: table.OriginalName, TableName: naming.TypeName(table.OriginalName)}
 
-		caser := cases.Title(language.Und, cases.NoLower)
-		columnName := caser.String(strings.ToLower(strings.TrimSpace(chunks[0])))
-		columnType := strings.ToLower(strings.TrimSpace(TypeMapper(definitionType, chunks[1])))
+	for _, column := range table.Columns {
+		if IsColumnIgnored(s2i.logger(), table.FileName, table.OriginalName, column.OriginalName, s2i.Config.IgnoreColumns) {
+			continue
+		}
 
+		columnType := strings.ToLower(TypeMapper(definitionType, column.Type))
 		if definitionType == "typescript" {
 			columnType = caser.String(columnType)
 		}
+		s2i.logger().Log(context.Background(), LevelTrace, "mapped column", "table", table.OriginalName, "column", column.OriginalName, "sql_type", column.Type, "type", columnType, "target", definitionType)
+
+		converted := Column{
+			Name:         naming.FieldName(table.OriginalName, column.OriginalName),
+			OriginalName: column.OriginalName,
+			Type:         columnType,
+			Comment:      column.Comment,
+			Constraints:  column.Constraints,
+		}
 
-		if IsColumnIgnored(fileName, columnName, s2i.Config.IgnoreColumns) {
-			continue
+		if jsonName := naming.JSONName(column.OriginalName); jsonName != "" && definitionType == "go" {
+			converted.Tags = map[string]string{"json": jsonName}
 		}
 
-		columns = append(columns, Column{
-			Name: columnName,
-			Type: columnType,
-		})
+		sql.Columns = append(sql.Columns, converted)
 	}
 
-	return columns, nil
+	return sql
 }
 
 // TypeMapper maps SQL column types to their corresponding TypeScript types.
@@ -279,6 +811,11 @@ func (s2i *SQL2Interface) ParseRowColumnDefinitions(definitionType string, fileN
 // - string: The corresponding typescript or go type.
 func TypeMapper(definitionType string, colType string) string {
 
+	// parameters like the length of VARCHAR(255) do not change the type
+	if baseType, _, hasParameters := strings.Cut(colType, "("); hasParameters {
+		colType = strings.TrimSpace(baseType)
+	}
+
 	if strings.Contains(strings.ToUpper(colType), "VARCHAR") {
 		colType = "VARCHAR"
 	}
@@ -339,7 +876,16 @@ func CreateInterface(sql SQL) string {
 	interfaceFields := ""
 	length := len(sql.Columns)
 	for i, column := range sql.Columns {
-		interfaceFields += fmt.Sprintf("\t%v: %v", column.Name, column.Type)
+		if column.Comment != "" {
+			interfaceFields += fmt.Sprintf("\t/** %v */\r\n", column.Comment)
+		}
+
+		optional := ""
+		if column.Optional {
+			optional = "?"
+		}
+
+		interfaceFields += fmt.Sprintf("\t%v%v: %v", column.Name, optional, column.Type)
 
 		if i < length-1 {
 			interfaceFields += ", "
@@ -353,6 +899,7 @@ func CreateInterface(sql SQL) string {
 
 // CreateStruct generates a Go struct based on the provided SQL table definition.
 // It iterates through the columns of the SQL table and constructs the struct fields.
+// Optional columns become pointer fields unless their type can already be nil, comments are written above the field and tags are written in alphabetical order.
 //
 // Parameters:
 // - sql: A SQL struct containing the table name and column details.
@@ -369,21 +916,70 @@ func CreateInterface(sql SQL) string {
 func CreateStruct(sql SQL) string {
 	structFields := ""
 	for _, column := range sql.Columns {
-		structFields += fmt.Sprintf("\t%v %v", column.Name, column.Type)
+		if column.Comment != "" {
+			structFields += fmt.Sprintf("\t// %v\r\n", column.Comment)
+		}
+
+		columnType := column.Type
+		if column.Optional && !IsNillableGoType(columnType) {
+			columnType = "*" + columnType
+		}
+
+		structFields += fmt.Sprintf("\t%v %v", column.Name, columnType)
+
+		if len(column.Tags) > 0 {
+			var tags []string
+			for _, key := range SortedKeys(column.Tags) {
+				tags = append(tags, fmt.Sprintf("%v:%q", key, column.Tags[key]))
+			}
+			structFields += fmt.Sprintf(" `%v`", strings.Join(tags, " "))
+		}
 
 		structFields += "\r\n"
 	}
 	return fmt.Sprintf("type %v struct {\n%v}", sql.TableName, structFields)
 }
 
+// IsValidPosition checks if a position of an arbitrary field is "start", "end", "after:<column>" or empty.
+func IsValidPosition(position string) bool {
+	position = strings.ToLower(strings.TrimSpace(position))
+	after, isAfter := strings.CutPrefix(position, "after:")
+	return position == "" || position == "start" || position == "end" || (isAfter && strings.TrimSpace(after) != "")
+}
+
+// IsNillableGoType checks if a Go type can already hold nil (pointers, slices, maps, channels, functions and interfaces).
+func IsNillableGoType(goType string) bool {
+	goType = strings.TrimSpace(goType)
+	for _, prefix := range []string{"*", "[]", "map[", "chan ", "func(", "interface{", "any"} {
+		if strings.HasPrefix(goType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 /* COMBINER */
 
+// collision policies for columns that exist in more than one table of a combiner
+const (
+	CollisionError     = "error"
+	CollisionKeepFirst = "keep_first"
+	CollisionKeepLast  = "keep_last"
+	CollisionPrefix    = "prefix"
+)
+
 type Combiner struct {
 	Tables              []string `json:"tables"`
 	Amount              int      `json:"amount"`
 	InterfaceName       string   `json:"interface_name"`
 	TableDefinitions    []SQL    `json:"table_definitions"`
 	ConvertSingleTables bool     `json:"convert_single_tables"`
+	OnCollision         string   `json:"on_collision"`
+}
+
+type ColumnCollision struct {
+	Column string   `json:"column"`
+	Tables []string `json:"tables"`
 }
 
 // LoadCombiner initializes and loads the combiner configuration from the SQL2Interface instance.
@@ -393,39 +989,38 @@ func (s2i *SQL2Interface) LoadCombiner() {
 	combinerConf := s2i.Config.CombineTables
 
 	// Handling of errors and edge cases
-	if combinerConf == nil {
-		fmt.Println("=> no combine_tables configuration found. skipping...")
-		return
-	}
-
 	if len(combinerConf) == 0 {
-		fmt.Println("=> no combine_tables configuration found. skipping...")
+		s2i.logger().Debug("no combine_tables configuration found, skipping")
 		return
 	}
 
 	s2i.Combiner = make(map[string][]Combiner)
 
-	// Iterating through the combine_tables configuration and populating the Combiner slice
-	for _, singleCombinerConf := range combinerConf {
+	// Iterating through the combine_tables configuration in the order of its keys, so combined structures are always generated in the same order
+	for _, key := range SortedKeys(combinerConf) {
+		singleCombinerConf := combinerConf[key]
 		s2i.Combiner["typescript"] = append(s2i.Combiner["typescript"], Combiner{
 			Tables:              singleCombinerConf.Tables,
 			Amount:              len(singleCombinerConf.Tables),
 			InterfaceName:       singleCombinerConf.Name,
 			ConvertSingleTables: singleCombinerConf.ConvertSingleTables,
+			OnCollision:         singleCombinerConf.OnCollision,
 		})
 		s2i.Combiner["go"] = append(s2i.Combiner["go"], Combiner{
 			Tables:              singleCombinerConf.Tables,
 			Amount:              len(singleCombinerConf.Tables),
 			InterfaceName:       singleCombinerConf.Name,
 			ConvertSingleTables: singleCombinerConf.ConvertSingleTables,
+			OnCollision:         singleCombinerConf.OnCollision,
 		})
 	}
 
 }
 
-// AddToCombiner checks if the SQL table definition's file name is in the list of tables to combine.
+// AddToCombiner checks if the SQL table definition is referenced by the list of tables to combine.
+// Entries of the list can be file names, table names or glob patterns for either of them (see MatchesTable).
 // If it is, the function appends the table definition to the corresponding combiner and returns true along with the index of the combiner.
-// If the file name is not found in any of the combiners, the function returns false and -1.
+// If the table is not found in any of the combiners, the function returns false and -1.
 //
 // Parameters:
 // - definition (SQL): The SQL table definition to be added to the combiner.
@@ -437,16 +1032,63 @@ func (s2i *SQL2Interface) AddToCombiner(definitionType string, definition SQL) (
 	inCombiner := false
 	combinerIndex := -1
 	for i, combiner := range s2i.Combiner[definitionType] {
-		if ValueInSlice(interface{}(definition.FileName), StringToInterfaceSlice(combiner.Tables)) {
-			s2i.Combiner[definitionType][i].TableDefinitions = append(s2i.Combiner[definitionType][i].TableDefinitions, definition)
-			inCombiner = true
-			combinerIndex = i
+		for _, table := range combiner.Tables {
+			if MatchesTable(table, definition) {
+				s2i.Combiner[definitionType][i].TableDefinitions = append(s2i.Combiner[definitionType][i].TableDefinitions, definition)
+				inCombiner = true
+				combinerIndex = i
+				break
+			}
 		}
 	}
 
 	return inCombiner, combinerIndex
 }
 
+// MatchesTable checks if a table reference from the configuration refers to the given SQL table definition.
+// A reference matches if it equals the file name, the original table name or the structure name of the definition or if it is a glob pattern
+// (e.g. "product_*" or "product*.sql") matching either of them. The comparison is case-insensitive.
+// Files in subdirectories of the input directory match with their path relative to the input directory (e.g. "billing/invoices.sql") and their base name.
+//
+// Parameters:
+// - reference (string): The file name, table name or glob pattern from the configuration.
+// - definition (SQL): The SQL table definition to be checked.
+//
+// Return:
+// - bool: Indicates whether the reference matches the table definition.
+func MatchesTable(reference string, definition SQL) bool {
+	return MatchPattern(reference, definition.FileName) || MatchPattern(reference, path.Base(definition.FileName)) ||
+		MatchPattern(reference, definition.OriginalName) || MatchPattern(reference, definition.TableName)
+}
+
+// UnmatchedTables returns the table references of a combiner that did not match any of its table definitions.
+func (combiner Combiner) UnmatchedTables() []string {
+	var unmatched []string
+	for _, table := range combiner.Tables {
+		found := false
+		for _, definition := range combiner.TableDefinitions {
+			if MatchesTable(table, definition) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			unmatched = append(unmatched, table)
+		}
+	}
+	return unmatched
+}
+
+// ResetCombiner removes the table definitions collected by previous conversions from all combiners.
+func (s2i *SQL2Interface) ResetCombiner() {
+	for _, combiners := range s2i.Combiner {
+		for i := range combiners {
+			combiners[i].TableDefinitions = nil
+		}
+	}
+}
+
 // ConvertSingleTable checks if the single table conversion is enabled for a combiner.
 //
 // Parameters:
@@ -458,26 +1100,143 @@ func (s2i *SQL2Interface) ConvertSingleTable(definitionType string, index int) b
 	return s2i.Combiner[definitionType][index].ConvertSingleTables
 }
 
+// FindColumnCollisions returns every column name that occurs in more than one of the given table definitions.
+// Column names are compared case-insensitively. The collisions are returned in the order their columns first appear.
+//
+// Parameters:
+// - interfaceDefinitions (SQL)...: The SQL table definitions to be checked.
+//
+// Return:
+// - []ColumnCollision: The colliding columns together with the names of the tables they appear in.
+func FindColumnCollisions(interfaceDefinitions ...SQL) []ColumnCollision {
+	var collisions []ColumnCollision
+	seen := make(map[string]int)
+
+	for _, definition := range interfaceDefinitions {
+		for _, column := range definition.Columns {
+			key := strings.ToLower(column.Name)
+			index, exists := seen[key]
+
+			if !exists {
+				seen[key] = len(collisions)
+				collisions = append(collisions, ColumnCollision{Column: column.Name})
+				index = seen[key]
+			}
+
+			collisions[index].Tables = append(collisions[index].Tables, definition.TableName)
+		}
+	}
+
+	var result []ColumnCollision
+	for _, collision := range collisions {
+		if len(collision.Tables) > 1 {
+			result = append(result, collision)
+		}
+	}
+
+	return result
+}
+
 // CombineTables combines multiple SQL table definitions into a single SQL table definition.
-// It takes an interface name and a variable number of SQL table definitions as input.
-// The function returns a slice of Column structs representing the combined SQL table definition.
+// Columns that exist in more than one table definition are resolved according to the collision policy:
+// "error" (the default) refuses to combine the tables, "keep_first" and "keep_last" keep only the first or last occurrence
+// and "prefix" prefixes every colliding column and its json tag with the name of its table.
 //
 // Parameters:
 // - interfaceName (string): The name of the interface that will be created for the combined table.
+// - collisionPolicy (string): The policy used for columns that exist in more than one table definition.
+// - naming (NamingConfig): The naming used to create the prefixed names of colliding columns.
 // - interfaceDefinitions (SQL)...: A variable number of SQL table definitions to be combined.
 //
 // Return:
 // - []Column: A slice of Column structs representing the combined SQL table definition.
-func CombineTables(interfaceName string, interfaceDefinitions ...SQL) []Column {
+// - []ColumnCollision: The columns that collided while combining the table definitions.
+// - error: An error if the collision policy is unknown or if it is "error" and columns collided.
+func CombineTables(interfaceName string, collisionPolicy string, naming NamingConfig, interfaceDefinitions ...SQL) ([]Column, []ColumnCollision, error) {
 	var newSql []Column
 
+	collisions := FindColumnCollisions(interfaceDefinitions...)
+	colliding := make(map[string]bool)
+	for _, collision := range collisions {
+		colliding[strings.ToLower(collision.Column)] = true
+	}
+
+	switch collisionPolicy {
+	case "", CollisionError:
+		if len(collisions) > 0 {
+			return nil, collisions, fmt.Errorf("columns collide in combined structure %v: %v", interfaceName, FormatColumnCollisions(collisions))
+		}
+	case CollisionKeepFirst, CollisionKeepLast, CollisionPrefix:
+	default:
+		return nil, collisions, fmt.Errorf("unknown on_collision policy '%v' for combined structure %v", collisionPolicy, interfaceName)
+	}
+
 	// Iterate through each SQL table definition and append its columns to the newSql slice.
 	for _, definition := range interfaceDefinitions {
-		newSql = append(newSql, definition.Columns...)
+		for _, column := range definition.Columns {
+			if colliding[strings.ToLower(column.Name)] && collisionPolicy == CollisionPrefix {
+				table := FirstNonEmpty(definition.OriginalName, definition.TableName)
+				original := FirstNonEmpty(column.OriginalName, column.Name)
+				column.Name = naming.PrefixedFieldName(table, original)
+
+				// the json tag is renamed as well, otherwise the fields share their key and are dropped by encoding/json
+				if jsonTag, hasJSONTag := column.Tags["json"]; hasJSONTag {
+					_, options, _ := strings.Cut(jsonTag, ",")
+					tags := map[string]string{}
+					for key, value := range column.Tags {
+						tags[key] = value
+					}
+					prefixed := table + "_" + original
+					tags["json"] = strings.TrimSuffix(FirstNonEmpty(naming.JSONName(prefixed), prefixed)+","+options, ",")
+					column.Tags = tags
+				}
+			}
+			newSql = append(newSql, column)
+		}
+	}
+
+	if collisionPolicy == CollisionKeepFirst || collisionPolicy == CollisionKeepLast {
+		newSql = DeduplicateColumns(newSql, collisionPolicy == CollisionKeepLast)
 	}
 
 	// Return the combined SQL table definition.
-	return newSql
+	return newSql, collisions, nil
+}
+
+// DeduplicateColumns removes columns whose name occurs more than once, comparing names case-insensitively.
+// If keepLast is false the first occurrence of a column is kept, otherwise the last one.
+func DeduplicateColumns(columns []Column, keepLast bool) []Column {
+	var result []Column
+	seen := make(map[string]bool)
+
+	if !keepLast {
+		for _, column := range columns {
+			key := strings.ToLower(column.Name)
+			if !seen[key] {
+				seen[key] = true
+				result = append(result, column)
+			}
+		}
+		return result
+	}
+
+	for i := len(columns) - 1; i >= 0; i-- {
+		key := strings.ToLower(columns[i].Name)
+		if !seen[key] {
+			seen[key] = true
+			result = append([]Column{columns[i]}, result...)
+		}
+	}
+	return result
+}
+
+// FormatColumnCollisions formats a list of column collisions for reporting, e.g. "Id (Product, Product_price)".
+func FormatColumnCollisions(collisions []ColumnCollision) string {
+	var parts []string
+	for _, collision := range collisions {
+		parts = append(parts, fmt.Sprintf("%v (%v)", collision.Column, strings.Join(collision.Tables, ", ")))
+	}
+	return strings.Join(parts, "; ")
 }
 
 // CombinerToStructure converts combined SQL table definitions to interfaces or structs.
@@ -485,31 +1244,55 @@ func CombineTables(interfaceName string, interfaceDefinitions ...SQL) []Column {
 // For each combiner, it creates a new SQL table definition with the combined columns and appends it to the output structure.
 // If the output type is TypeScript, it adds the interface definition to the TypeScript section of the output structure.
 // If the output type is Go, it adds the struct definition to the Go section of the output structure.
-// Finally, it returns nil to indicate successful execution.
+// Column collisions are reported and resolved according to the combiner's on_collision policy.
+// A combiner referencing a table that was never found is reported as an error instead of producing an incomplete structure.
+// Combiners that cannot be combined are skipped and their errors are returned together as ConversionErrors of CombineErrors.
 func (s2i *SQL2Interface) CombinerToStructure(output *ConvertedStructure) error {
-	fmt.Println("=> attempting to convert combined tables to interfaces...")
+	s2i.logger().Debug("converting combined structures")
+
+	var combineErrors ConversionErrors
 
-	for outputType, combinersPerType := range s2i.Combiner {
-		for _, singleCombiner := range combinersPerType {
+	for _, outputType := range SortedKeys(s2i.Combiner) {
+		for _, singleCombiner := range s2i.Combiner[outputType] {
 			structureName := singleCombiner.InterfaceName
 			tableDefinitions := singleCombiner.TableDefinitions
-			combinedColumns := CombineTables(structureName, tableDefinitions...)
+
+			if unmatched := singleCombiner.UnmatchedTables(); len(unmatched) > 0 {
+				combineErrors.Add(CombineError{Structure: structureName, Target: outputType, Err: fmt.Errorf("tables were not found: %v", strings.Join(unmatched, ", "))})
+				continue
+			}
+
+			combinedColumns, collisions, combineError := CombineTables(structureName, singleCombiner.OnCollision, s2i.Naming(outputType), tableDefinitions...)
+
+			if combineError != nil {
+				combineErrors.Add(CombineError{Structure: structureName, Target: outputType, Err: combineError})
+				continue
+			}
+
+			if len(collisions) > 0 {
+				s2i.logger().Info("column collisions resolved", "structure", structureName, "target", outputType, "policy", singleCombiner.OnCollision, "collisions", FormatColumnCollisions(collisions))
+			}
 
 			newSQL := SQL{
 				TableName: structureName,
 				Columns:   combinedColumns,
 			}
+			s2i.AddArbitraryFields(&newSQL, outputType)
+			s2i.SanitizeStructure(outputType, &newSQL)
 
 			if outputType == "typescript" {
-				(*output).StructureDefinition["typescript"] = output.StructureDefinition["typescript"] + "\n\n" + CreateInterface(newSQL)
-				output.StructureNames["typescript"] = append(output.StructureNames["typescript"], structureName)
+				output.Add("typescript", newSQL, CreateInterface(newSQL))
 			} else if outputType == "go" {
-				(*output).StructureDefinition["go"] = output.StructureDefinition["go"] + "\n\n" + CreateStruct(newSQL)
-				(*output).StructureNames["go"] = append(output.StructureNames["go"], structureName)
+				output.Add("go", newSQL, CreateStruct(newSQL))
 			}
 		}
 	}
-	fmt.Println("    => conversion successful")
+
+	if len(combineErrors) > 0 {
+		return combineErrors
+	}
+
+	s2i.logger().Debug("combined structures converted")
 	return nil
 }
 
@@ -556,43 +1339,203 @@ func ValidateInputOutput(input string, output string) error {
 // Parameters:
 // - sql: A pointer to the SQL struct representing the table definition to which arbitrary fields will be added.
 //
-// The function checks if the file name of the SQL table matches the file name specified in the configuration.
-// If a match is found, it prints a message indicating the addition of the arbitrary field and appends the field to the SQL table definition.
+// The keys of arbitrary_fields can be file names, table names, names of combined structures or glob patterns for any of them.
+// If a key matches the SQL table definition, every field of that key is inserted at its configured position.
+// Targets and fields are processed in alphabetical order so the output is stable between runs.
+// Fields are only added to the structure they are configured for, the arbitrary fields of a table are not carried into combined structures.
+// A field whose name is already used by a column of the structure is skipped with a warning.
 func (s2i *SQL2Interface) AddArbitraryFields(sql *SQL, definitionType string) {
-	for fileName, fields := range s2i.Config.ArbitraryFields {
-		for _, value := range fields {
-			if fileName == sql.FileName {
-				var newCol Column
-				newCol.Name = value.Name
-
-				if definitionType == "typescript" {
-					newCol.Type = value.TypeTs
-				} else {
-					newCol.Type = value.TypeGo
-				}
-				if strings.TrimSpace(newCol.Type) == "" || strings.TrimSpace(newCol.Name) == "" {
-					continue
-				}
-				fmt.Printf("  => adding arbitrary field: %v : %v for type %v\n", newCol.Name, newCol.Type, definitionType)
-				sql.Columns = append(sql.Columns, newCol)
+	// the columns may be shared with the definitions of the combiner
+	sql.Columns = append([]Column(nil), sql.Columns...)
+
+	for _, target := range SortedKeys(s2i.Config.ArbitraryFields) {
+		if !MatchesTable(target, *sql) {
+			continue
+		}
+
+		fields := s2i.Config.ArbitraryFields[target]
+		for _, fieldKey := range SortedKeys(fields) {
+			value := fields[fieldKey]
+
+			var newCol Column
+			newCol.Name = value.Name
+			newCol.Optional = value.Optional
+			newCol.Comment = value.Comment
+			newCol.Tags = value.Tags
+
+			if definitionType == "typescript" {
+				newCol.Type = value.TypeTs
+			} else {
+				newCol.Type = value.TypeGo
+			}
+			if strings.TrimSpace(newCol.Type) == "" || strings.TrimSpace(newCol.Name) == "" {
+				continue
 			}
+			if hasColumn(sql.Columns, newCol.Name) {
+				s2i.logger().Warn("skipping arbitrary field, the structure already has a field with this name", "structure", sql.TableName, "field", newCol.Name, "target", target)
+				continue
+			}
+			s2i.logger().Debug("adding arbitrary field", "structure", sql.TableName, "field", newCol.Name, "type", newCol.Type, "target", definitionType)
+
+			inserted, insertError := InsertColumn(sql.Columns, newCol, value.Position)
+			if insertError != nil {
+				s2i.logger().Warn("appending arbitrary field to the end", "structure", sql.TableName, "field", newCol.Name, "error", insertError)
+			}
+			sql.Columns = inserted
 		}
 	}
 }
 
+// InsertColumn inserts a column into a slice of columns at the given position.
+// The position can be "start", "end" (the default) or "after:<column>" to insert the column after an existing one.
+// If the position is invalid or the referenced column does not exist, the column is appended and an error is returned.
+//
+// Parameters:
+// - columns: The existing columns.
+// - column: The column to be inserted.
+// - position: The position at which the column is inserted.
+//
+// Return:
+// - []Column: The columns including the inserted column.
+// - error: An error if the column could not be inserted at the requested position.
+func InsertColumn(columns []Column, column Column, position string) ([]Column, error) {
+	position = strings.TrimSpace(position)
+
+	switch {
+	case position == "" || strings.EqualFold(position, "end"):
+		return append(columns, column), nil
+	case strings.EqualFold(position, "start"):
+		return append([]Column{column}, columns...), nil
+	case strings.HasPrefix(strings.ToLower(position), "after:"):
+		after := strings.TrimSpace(position[len("after:"):])
+		for i, existing := range columns {
+			if strings.EqualFold(existing.Name, after) {
+				result := append([]Column{}, columns[:i+1]...)
+				result = append(result, column)
+				return append(result, columns[i+1:]...), nil
+			}
+		}
+		return append(columns, column), fmt.Errorf("column %v referenced by position '%v' not found", after, position)
+	default:
+		return append(columns, column), fmt.Errorf("invalid position '%v'", position)
+	}
+}
+
+// hasColumn checks if one of the columns has the given name. The comparison is case-insensitive.
+func hasColumn(columns []Column, name string) bool {
+	for _, column := range columns {
+		if strings.EqualFold(column.Name, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 /* MAIN */
 
 // Run starts the conversion process for SQL files to TypeScript and Go interfaces/structs.
-// It retrieves the list of SQL files from the input directory specified in the configuration.
-// If any error occurs during file retrieval, it prints the error message.
-// Finally, it calls the Convert function to perform the actual conversion.
-func (s2i *SQL2Interface) Run() {
-	sourceDir := s2i.Config.Input
-	files, err := GetFiles(sourceDir)
+// It parses the tables of the configured input (see ParseInput), converts them and writes the output files.
+// Tables that could not be converted are skipped, the remaining output files are written anyway.
+//
+// Return:
+// - error: A ConfigError if the input could not be read, otherwise ConversionErrors containing every problem found while converting and writing, or nil.
+func (s2i *SQL2Interface) Run() error {
+	generated, generateError := s2i.GenerateInput()
+
+	if _, isConfigError := generateError.(ConfigError); isConfigError {
+		return generateError
+	}
+
+	var conversionErrors ConversionErrors
+	conversionErrors.Add(generateError)
+
+	_, writeError := s2i.WriteFiles(generated)
+	conversionErrors.Add(writeError)
+
+	return conversionErrors.Err()
+}
+
+// Check converts the tables of the configured input in memory and compares the result with the output files on disk.
+// Generated files in the output directories that are not generated anymore are reported as stale, see OrphanedFiles. Nothing is written to disk.
+//
+// Return:
+// - []string: A unified diff for every stale output file. The slice is empty if all output files are up to date.
+// - error: A ConfigError if the input could not be read, otherwise the errors of GenerateInput, see GenerateInput.
+func (s2i *SQL2Interface) Check() ([]string, error) {
+	generated, err := s2i.GenerateInput()
+
+	if _, isConfigError := err.(ConfigError); isConfigError {
+		return nil, err
+	}
+
+	return DiffFiles(generated, s2i.Config.OutputDirs()...), err
+}
+
+// GenerateInput converts the tables of the configured input in memory without writing anything to disk.
+//
+// Return:
+// - []GeneratedFile: The files that would be written for the configured outputs.
+// - error: A ConfigError if the input could not be read, otherwise ConversionErrors containing a ParseError for every file
+// and a CombineError for every combined structure that could not be converted, or nil.
+func (s2i *SQL2Interface) GenerateInput() ([]GeneratedFile, error) {
+	tables, err := s2i.ParseInput()
+
+	if _, isConfigError := err.(ConfigError); isConfigError {
+		return nil, err
+	}
+
+	var conversionErrors ConversionErrors
+	conversionErrors.Add(err)
+
+	generated, generateError := s2i.GenerateTables(tables)
+	conversionErrors.Add(generateError)
+
+	return generated, conversionErrors.Err()
+}
+
+// ParseInput parses the tables of the configured input: the tables of input_schema if it is set (see LoadSchema),
+// otherwise the SQL files of the input directory (see ParseFiles). Tables of ignored files are skipped in both cases.
+//
+// Return:
+// - []SQL: The parsed tables.
+// - error: A ConfigError if the input directory or the input schema could not be read, otherwise the errors of ParseFiles, see ParseFiles.
+func (s2i *SQL2Interface) ParseInput() ([]SQL, error) {
+	if s2i.Config.InputSchema == "" {
+		files, err := s2i.inputFiles()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return s2i.ParseFiles(files)
+	}
+
+	schema, loadError := LoadSchemaFile(s2i.Config.InputSchema)
+	if loadError != nil {
+		return nil, s2i.Config.errorAt("input_schema", "%v", loadError)
+	}
+
+	var tables []SQL
+	for _, table := range schema.Tables {
+		if !IsFileIgnored(s2i.logger(), table.FileName, s2i.Config.IgnoreFiles) {
+			tables = append(tables, table)
+		}
+	}
+
+	return tables, nil
+}
+
+// inputFiles returns the files of the configured input directory, see FindInputFiles.
+// If the input directory could not be read, a ConfigError located at the input key is returned.
+func (s2i *SQL2Interface) inputFiles() ([]InputFile, error) {
+	options := s2i.Config.InputOptions()
+	options.Logger = s2i.logger()
+	files, err := FindInputFiles(s2i.Config.Input, options)
 
 	if err != nil {
-		fmt.Println(err)
+		return nil, s2i.Config.errorAt("input", "input directory '%v' could not be read: %v", s2i.Config.Input, err)
 	}
 
-	s2i.Convert(files)
+	return files, nil
 }
@@ -0,0 +1,248 @@
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func combinerTestTables() []SQL {
+	return []SQL{
+		{TableName: "Product", Columns: []Column{{Name: "Id", Type: "int"}, {Name: "Name", Type: "string"}}},
+		{TableName: "Product_price", Columns: []Column{{Name: "Id", Type: "int64"}, {Name: "Price", Type: "float32"}}},
+	}
+}
+
+func TestCombineTablesCollisionError(t *testing.T) {
+	_, collisions, err := CombineTables("Products", CollisionError, NamingConfig{}, combinerTestTables()...)
+
+	assert.Error(t, err)
+	assert.Equal(t, []ColumnCollision{{Column: "Id", Tables: []string{"Product", "Product_price"}}}, collisions)
+}
+
+func TestCombineTablesCollisionPolicies(t *testing.T) {
+	keepFirst, _, err := CombineTables("Products", CollisionKeepFirst, NamingConfig{}, combinerTestTables()...)
+	assert.NoError(t, err)
+	assert.Equal(t, []Column{{Name: "Id", Type: "int"}, {Name: "Name", Type: "string"}, {Name: "Price", Type: "float32"}}, keepFirst)
+
+	keepLast, _, err := CombineTables("Products", CollisionKeepLast, NamingConfig{}, combinerTestTables()...)
+	assert.NoError(t, err)
+	assert.Equal(t, []Column{{Name: "Name", Type: "string"}, {Name: "Id", Type: "int64"}, {Name: "Price", Type: "float32"}}, keepLast)
+
+	prefixed, _, err := CombineTables("Products", CollisionPrefix, NamingConfig{}, combinerTestTables()...)
+	assert.NoError(t, err)
+	assert.Equal(t, []Column{{Name: "ProductId", Type: "int"}, {Name: "Name", Type: "string"}, {Name: "ProductPriceId", Type: "int64"}, {Name: "Price", Type: "float32"}}, prefixed)
+}
+
+func TestCombineTablesPrefixedTags(t *testing.T) {
+	tables, _ := ParseDDL(strings.NewReader("CREATE TABLE product (id INT, `2fa_enabled` BOOL); CREATE TABLE product_price (id INT, price DECIMAL);"), DDLOptions{})
+	conf := &Config{
+		Output:        OutputConfig{Go: &GoOutput{PackageName: "models"}},
+		CombineTables: map[string]TableCombine{"products": {Name: "Products", Tables: []string{"product", "product_price"}, OnCollision: CollisionPrefix, ConvertSingleTables: true}},
+		Naming:        map[string]NamingConfig{"go": {Fields: NamingPascal, JSONTags: NamingSnake, Initialisms: true}},
+	}
+
+	content, err := Render(tables, "go", conf)
+	assert.NoError(t, err)
+	combined := string(content)[strings.Index(string(content), "type Products struct"):]
+
+	seen := make(map[string]bool)
+	for _, tag := range regexp.MustCompile(`json:"([^"]*)"`).FindAllStringSubmatch(combined, -1) {
+		assert.False(t, seen[tag[1]], "json tag %v is used twice", tag[1])
+		seen[tag[1]] = true
+	}
+	assert.Contains(t, combined, "\tProductID int `json:\"product_id\"`\r\n")
+	assert.Contains(t, combined, "\tProductPriceID int `json:\"product_price_id\"`\r\n")
+
+	// keys quoted for the single tables are not quoted again in the combined structure
+	content, _ = Render(tables, "typescript", conf)
+	assert.Contains(t, string(content), "interface Products {\n\tProductId: Number, \r\n\t\"2Fa_enabled\": Boolean, ")
+}
+
+func TestArbitraryFieldsCombined(t *testing.T) {
+	tables, _ := ParseDDL(strings.NewReader("CREATE TABLE product (id INT, name TEXT); CREATE TABLE product_price (id INT, price DECIMAL);"), DDLOptions{})
+	conf := &Config{
+		Output:        OutputConfig{Go: &GoOutput{PackageName: "models"}},
+		CombineTables: map[string]TableCombine{"products": {Name: "Products", Tables: []string{"product", "product_price"}, OnCollision: CollisionKeepFirst, ConvertSingleTables: true}},
+		ArbitraryFields: map[string]map[string]ArbitraryField{
+			"product*":      {"extra": {Name: "Extra", TypeGo: "string", TypeTs: "String"}, "name": {Name: "Name", TypeGo: "string", TypeTs: "String"}},
+			"product_price": {"currency": {Name: "Currency", TypeGo: "string", TypeTs: "String"}},
+		},
+	}
+
+	content, err := Render(tables, "go", conf)
+	assert.NoError(t, err)
+	output := string(content)
+	product := output[strings.Index(output, "type Product struct"):strings.Index(output, "type Product_price struct")]
+	combined := output[strings.Index(output, "type Products struct"):]
+
+	assert.Equal(t, 1, strings.Count(product, "\tExtra string"))
+	assert.Equal(t, 1, strings.Count(product, "\tName string"), "existing columns are not added twice")
+	assert.Contains(t, output[strings.Index(output, "type Product_price struct"):], "\tCurrency string")
+
+	// the combined structure only gets the fields configured for itself, "product*" matches it but is added once
+	assert.Equal(t, 1, strings.Count(combined, "\tExtra string"))
+	assert.Equal(t, 1, strings.Count(combined, "\tName string"))
+	assert.NotContains(t, combined, "Currency")
+}
+
+func TestMatchesTable(t *testing.T) {
+	definition := SQL{FileName: "product_prices.sql", TableName: "Product_price"}
+
+	assert.True(t, MatchesTable("product_prices.sql", definition))
+	assert.True(t, MatchesTable("product_price", definition))
+	assert.True(t, MatchesTable("product_*", definition))
+	assert.False(t, MatchesTable("product.sql", definition))
+
+	nested := SQL{FileName: "billing/invoices.sql", TableName: "Invoices"}
+	assert.True(t, MatchesTable("invoices.sql", nested))
+	assert.True(t, MatchesTable("billing/*.sql", nested))
+}
+
+func TestParseSQLInvalidInput(t *testing.T) {
+	s2i := &SQL2Interface{Config: &Config{}}
+
+	_, err := s2i.ParseSQL("go", "README.md", "# Schema\nThe tables of the shop.")
+	assert.EqualError(t, err, "README.md: invalid CREATE statement found")
+
+	_, err = s2i.ParseSQL("go", "users.sql", "CREATE TABLE users")
+	assert.EqualError(t, err, "users.sql: table users: no column definitions found in CREATE statement")
+
+	_, err = s2i.ParseSQL("go", "users.sql", "CREATE TABLE users (id)")
+	assert.EqualError(t, err, "users.sql: table users: invalid column definition 'id', expected a column name and type")
+
+	var parseError ParseError
+	assert.ErrorAs(t, err, &parseError)
+	assert.Equal(t, ParseError{File: "users.sql", Table: "users", Err: parseError.Err}, parseError)
+}
+
+func TestParseRawTableName(t *testing.T) {
+	s2i := &SQL2Interface{Config: &Config{}}
+
+	for statement, expected := range map[string]string{
+		"CREATE TABLE NOTIFICATIONS":                   "notifications",
+		"CREATE TABLE TABLES":                          "tables",
+		"create table if not exists EXISTS_LOG":        "exists_log",
+		"CREATE  TABLE\n  IF NOT EXISTS CREATED_ITEMS": "created_items",
+	} {
+		assert.Equal(t, expected, s2i.ParseRawTableName(statement), statement)
+	}
+
+	table, err := s2i.ParseTable("notifications.sql", "CREATE TABLE notifications (id INT)")
+	assert.NoError(t, err)
+	assert.Equal(t, "Notifications", s2i.ConvertTable("go", table).TableName)
+}
+
+func TestCombinerUnmatchedTables(t *testing.T) {
+	combiner := Combiner{
+		Tables:           []string{"product", "product_price*"},
+		TableDefinitions: []SQL{{FileName: "product_prices.sql", TableName: "Product_price"}},
+	}
+
+	assert.Equal(t, []string{"product"}, combiner.UnmatchedTables())
+}
+
+func TestInsertColumn(t *testing.T) {
+	columns := []Column{{Name: "Id"}, {Name: "Email"}, {Name: "Name"}}
+
+	start, err := InsertColumn(columns, Column{Name: "New"}, "start")
+	assert.NoError(t, err)
+	assert.Equal(t, []Column{{Name: "New"}, {Name: "Id"}, {Name: "Email"}, {Name: "Name"}}, start)
+
+	after, err := InsertColumn(columns, Column{Name: "New"}, "after:email")
+	assert.NoError(t, err)
+	assert.Equal(t, []Column{{Name: "Id"}, {Name: "Email"}, {Name: "New"}, {Name: "Name"}}, after)
+	assert.Equal(t, []Column{{Name: "Id"}, {Name: "Email"}, {Name: "Name"}}, columns)
+
+	missing, err := InsertColumn(columns, Column{Name: "New"}, "after:missing")
+	assert.Error(t, err)
+	assert.Equal(t, Column{Name: "New"}, missing[len(missing)-1])
+}
+
+func TestCreateStructArbitraryFieldOptions(t *testing.T) {
+	sql := SQL{TableName: "Users", Columns: []Column{
+		{Name: "Id", Type: "int"},
+		{Name: "Orders", Type: "[]Order", Optional: true},
+		{Name: "Total", Type: "float64", Optional: true, Comment: "sum of all orders", Tags: map[string]string{"json": "total,omitempty", "db": "-"}},
+		{Name: "Unused", Type: "int"},
+	}}
+
+	expected := "type Users struct {\n\tId int\r\n\tOrders []Order\r\n\t// sum of all orders\r\n\tTotal *float64 `db:\"-\" json:\"total,omitempty\"`\r\n\tUnused int\r\n}"
+	assert.Equal(t, expected, CreateStruct(sql))
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "sql")
+	output := filepath.Join(dir, "models")
+	assert.NoError(t, os.MkdirAll(input, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(input, "users.sql"), []byte("CREATE TABLE users (id INT)"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(input, "orders.sql"), []byte("CREATE TABLE orders (id INT)"), 0644))
+
+	singleFile := false
+	s2i := NewSQL2InterfaceFromConfig(&Config{
+		Input:      input,
+		SingleFile: &singleFile,
+		Output:     OutputConfig{Go: &GoOutput{OutputDir: output, PackageName: "models"}},
+	})
+	assert.NoError(t, s2i.Run())
+	// hand written files are never reported
+	assert.NoError(t, os.WriteFile(filepath.Join(output, "helpers.go"), []byte("package models\n"), 0644))
+
+	diffs, err := s2i.Check()
+	assert.NoError(t, err)
+	assert.Empty(t, diffs, "up to date")
+
+	users := filepath.Join(output, "users.go")
+	assert.NoError(t, os.WriteFile(users, []byte(GeneratedHeader+"\n\npackage models\n"), 0644))
+	diffs, _ = s2i.Check()
+	assert.Len(t, diffs, 1, "stale")
+	assert.Contains(t, diffs[0], "+++ "+users+" (generated)")
+
+	assert.NoError(t, os.Remove(users))
+	diffs, _ = s2i.Check()
+	assert.Len(t, diffs, 1, "missing")
+	assert.Contains(t, diffs[0], "+type Users struct {")
+
+	assert.NoError(t, s2i.Run())
+	assert.NoError(t, os.Remove(filepath.Join(input, "orders.sql")))
+	diffs, _ = s2i.Check()
+	assert.Len(t, diffs, 1, "orphaned")
+	assert.Contains(t, diffs[0], "--- "+filepath.Join(output, "orders.go"))
+	assert.Contains(t, diffs[0], "-type Orders struct {")
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "models")
+	s2i := NewSQL2InterfaceFromConfig(&Config{})
+	generated := []GeneratedFile{
+		{Target: "go", Dir: dir, Name: "users.go", Content: GeneratedHeader + "\n\npackage models\n"},
+		{Target: "go", Dir: dir, Name: "helpers.go", Content: GeneratedHeader + "\n\npackage models\n"},
+	}
+
+	// the missing output directory is created
+	written, err := s2i.WriteFiles(generated[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, 1, written)
+
+	// hand written files are not overwritten
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "helpers.go"), []byte("package models\n"), 0644))
+	written, err = s2i.WriteFiles(generated)
+	assert.ErrorIs(t, err, ErrNotGenerated)
+	assert.Equal(t, 0, written, "unchanged files are not written")
+
+	content, _ := os.ReadFile(filepath.Join(dir, "helpers.go"))
+	assert.Equal(t, "package models\n", string(content))
+
+	s2i.Force = true
+	written, err = s2i.WriteFiles(generated)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, written)
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 2, "no temporary files are left")
+}
@@ -0,0 +1,320 @@
+package src
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"strings"
+)
+
+// createTablePattern matches the start of a CREATE TABLE statement.
+var createTablePattern = regexp.MustCompile(`(?i)^CREATE\s+TABLE\s`)
+
+// tableStatementPattern matches CREATE statements of tables that are not supported, e.g. CREATE TEMPORARY TABLE.
+var tableStatementPattern = regexp.MustCompile(`(?i)^CREATE\s[^(]*\bTABLE\b`)
+
+// otherStatementPattern matches the start of SQL statements that do not define tables and are skipped, e.g. CREATE INDEX or INSERT.
+var otherStatementPattern = regexp.MustCompile(`(?i)^(ALTER|ANALYZE|BEGIN|CALL|COMMENT|COMMIT|CREATE|DELETE|DELIMITER|DO|DROP|END|EXPLAIN|GRANT|INSERT|LOCK|PRAGMA|REPLACE|REVOKE|ROLLBACK|SAVEPOINT|SELECT|SET|SHOW|START|TRUNCATE|UNLOCK|UPDATE|USE|VACUUM|WITH)\b`)
+
+// DDLOptions controls how ParseDDL reads a DDL.
+type DDLOptions struct {
+	// FileName is the name the tables are parsed from, used in errors and to match the rules of the configuration, e.g. "schema.sql"
+	FileName string
+}
+
+// ParseDDL parses every CREATE TABLE statement of a DDL without touching the filesystem.
+// Statements are separated by semicolons outside of strings and comments, statements that do not define tables (e.g. CREATE INDEX or INSERT) are skipped.
+// "--" comments on the line of a column become the comment of the column. The tables do not depend on an output type, see ParseTable. Use Render to convert them into code.
+//
+// Parameters:
+// - r: The reader the DDL is read from, e.g. a strings.Reader.
+// - opts: The options of the DDL.
+//
+// Return:
+// - []SQL: The tables in the order of the DDL. Tables that could not be parsed are skipped.
+// - error: An error if the DDL could not be read, or ConversionErrors containing a ParseError for every statement that could not be split or parsed,
+// e.g. an unterminated string, an unknown statement or an invalid table.
+func ParseDDL(r io.Reader, opts DDLOptions) ([]SQL, error) {
+	content, readError := io.ReadAll(r)
+	if readError != nil {
+		return nil, readError
+	}
+
+	s2i := &SQL2Interface{Config: &Config{}}
+	return s2i.ParseStatements(opts.FileName, string(content))
+}
+
+// ParseStatements parses every CREATE TABLE statement of the content of a SQL file, see ParseDDL.
+// Input files are parsed by it as well, so a file may contain several tables and other statements like DROP TABLE or CREATE INDEX.
+//
+// Parameters:
+// - fileName: The name of the SQL file, used in errors and to match the rules of the configuration.
+// - ddl: The content of the SQL file.
+//
+// Return:
+// - []SQL: The tables in the order of the file. Tables that could not be parsed are skipped.
+// - error: ConversionErrors containing a ParseError for every statement that could not be split or parsed, or nil.
+func (s2i *SQL2Interface) ParseStatements(fileName string, ddl string) ([]SQL, error) {
+	var tables []SQL
+	var conversionErrors ConversionErrors
+
+	statements, splitError := SplitStatements(ddl)
+	for _, statement := range statements {
+		// comments in front of the statement, e.g. a file header, belong to the statement
+		code := strings.TrimSpace(removeSQLComments(statement))
+
+		switch {
+		case code == "":
+			continue
+		case createTablePattern.MatchString(code):
+			table, parseError := s2i.ParseTable(fileName, statement)
+			if parseError != nil {
+				conversionErrors.Add(parseError)
+				continue
+			}
+			tables = append(tables, table)
+		case tableStatementPattern.MatchString(code):
+			conversionErrors.Add(ParseError{File: fileName, Err: fmt.Errorf("unsupported table statement '%v'", statementStart(code))})
+		case !otherStatementPattern.MatchString(code):
+			conversionErrors.Add(ParseError{File: fileName, Err: fmt.Errorf("unknown statement '%v'", statementStart(code))})
+		}
+	}
+
+	if splitError != nil {
+		conversionErrors.Add(ParseError{File: fileName, Err: splitError})
+	}
+
+	return tables, conversionErrors.Err()
+}
+
+// statementStart returns the first line of a statement shortened for error messages.
+func statementStart(statement string) string {
+	start, _, _ := strings.Cut(statement, "\n")
+	if len(start) > 40 {
+		start = start[:40] + "..."
+	}
+	return strings.TrimSpace(start)
+}
+
+// SplitStatements splits a DDL into its statements separated by semicolons. Semicolons within strings, quoted names and comments
+// do not end a statement, the comments are kept so ParseTable can add them to the columns. Empty statements are skipped.
+//
+// Parameters:
+// - ddl: The DDL to be split.
+//
+// Return:
+// - []string: The statements in the order of the DDL.
+// - error: An error if a string or comment is not terminated, the statements before it are returned nevertheless.
+func SplitStatements(ddl string) ([]string, error) {
+	var statements []string
+	var statement strings.Builder
+
+	flush := func() {
+		if content := strings.TrimSpace(statement.String()); content != "" {
+			statements = append(statements, content)
+		}
+		statement.Reset()
+	}
+
+	segments, scanError := scanSQL(ddl)
+	for _, segment := range segments {
+		if segment.Kind != sqlCode {
+			statement.WriteString(segment.Text)
+			continue
+		}
+
+		for i, part := range strings.Split(segment.Text, ";") {
+			if i > 0 {
+				flush()
+			}
+			statement.WriteString(part)
+		}
+	}
+
+	if scanError != nil {
+		return statements, scanError
+	}
+	flush()
+
+	return statements, nil
+}
+
+// sqlSegmentKind is the kind of a part of a SQL text, see scanSQL.
+type sqlSegmentKind int
+
+const (
+	sqlCode sqlSegmentKind = iota
+	// sqlString is a string literal or a quoted name, including its quotes
+	sqlString
+	// sqlLineComment is a "--" comment up to the end of its line, sqlBlockComment a comment enclosed in "/*" and "*/"
+	sqlLineComment
+	sqlBlockComment
+)
+
+// sqlSegment is a part of a SQL text together with the line it starts in.
+type sqlSegment struct {
+	Kind sqlSegmentKind
+	Text string
+	Line int
+}
+
+// scanSQL splits a SQL text into code, strings and comments. Strings and quoted names are enclosed in single quotes,
+// double quotes or backticks, a doubled quote within them does not end them.
+//
+// Parameters:
+// - sql: The SQL text.
+//
+// Return:
+// - []sqlSegment: The segments, which joined result in the SQL text again.
+// - error: An error if the last string or block comment is not terminated. The segments contain it nevertheless.
+func scanSQL(sql string) ([]sqlSegment, error) {
+	var segments []sqlSegment
+	kind := sqlCode
+	var quote byte
+	start, line, startLine := 0, 1, 1
+
+	add := func(end int, next sqlSegmentKind) {
+		if end > start {
+			segments = append(segments, sqlSegment{Kind: kind, Text: sql[start:end], Line: startLine})
+		}
+		kind, start, startLine = next, end, line
+	}
+
+	for i := 0; i < len(sql); i++ {
+		char := sql[i]
+
+		switch kind {
+		case sqlCode:
+			switch {
+			case char == '\'' || char == '"' || char == '`':
+				add(i, sqlString)
+				quote = char
+			case strings.HasPrefix(sql[i:], "--"):
+				add(i, sqlLineComment)
+			case strings.HasPrefix(sql[i:], "/*"):
+				add(i, sqlBlockComment)
+				i++
+			}
+		case sqlString:
+			if char == quote && i+1 < len(sql) && sql[i+1] == quote {
+				i++
+			} else if char == quote {
+				add(i+1, sqlCode)
+			}
+		case sqlLineComment:
+			if char == '\n' {
+				add(i, sqlCode)
+			}
+		case sqlBlockComment:
+			if strings.HasPrefix(sql[i:], "*/") {
+				i++
+				add(i+1, sqlCode)
+			}
+		}
+
+		if char == '\n' {
+			line++
+		}
+	}
+
+	unterminated := map[sqlSegmentKind]string{sqlString: "string", sqlBlockComment: "comment"}[kind]
+	unterminatedLine := startLine
+	add(len(sql), sqlCode)
+
+	if unterminated != "" {
+		return segments, fmt.Errorf("unterminated %v starting in line %v", unterminated, unterminatedLine)
+	}
+	return segments, nil
+}
+
+// removeSQLComments removes the comments of a SQL text. Block comments are replaced by spaces, so the lines of the text are kept.
+func removeSQLComments(sql string) string {
+	var code strings.Builder
+
+	segments, _ := scanSQL(sql)
+	for _, segment := range segments {
+		switch segment.Kind {
+		case sqlLineComment:
+		case sqlBlockComment:
+			code.WriteString(blankSQL(segment.Text))
+		default:
+			code.WriteString(segment.Text)
+		}
+	}
+
+	return code.String()
+}
+
+// blankSQL replaces every character except line breaks with a space.
+func blankSQL(text string) string {
+	return strings.Map(func(char rune) rune {
+		if char == '\n' {
+			return char
+		}
+		return ' '
+	}, text)
+}
+
+// Render converts tables parsed by ParseDDL into the content of a single file of an output type without touching the filesystem.
+// The configuration is applied like in the conversion of files: naming, ignore_columns, arbitrary_fields, combine_tables,
+// export_types and package_name. The input and output directories, single_file and mirror_input_dirs are not used.
+//
+// Parameters:
+// - tables: The tables to be converted.
+// - target: The output type, "typescript" or "go".
+// - conf: The configuration, nil for the default configuration.
+//
+// Return:
+// - []byte: The content of the file. Combined structures that could not be created are skipped.
+// - error: An error if the target is unknown, or ConversionErrors containing a CombineError for every combined structure that could not be created.
+func Render(tables []SQL, target string, conf *Config) ([]byte, error) {
+	if target != "typescript" && target != "go" {
+		return nil, fmt.Errorf("unknown target '%v', expected typescript or go", target)
+	}
+
+	if conf == nil {
+		conf = &Config{}
+	}
+
+	// all structures are rendered into a single file
+	renderConf := *conf
+	renderConf.MirrorInputDirs = false
+
+	s2i := NewSQL2InterfaceFromConfig(&renderConf)
+	outputs, convertError := s2i.ConvertTables(tables)
+
+	// combined structures are converted for every output type, only the errors of the rendered one are returned
+	var targetErrors ConversionErrors
+	if conversionErrors, isList := convertError.(ConversionErrors); isList {
+		for _, conversionError := range conversionErrors {
+			var combineError CombineError
+			if errors.As(conversionError, &combineError) && combineError.Target != target {
+				continue
+			}
+			targetErrors.Add(conversionError)
+		}
+	}
+
+	return []byte(s2i.FileContent(target, "", *outputs[""])), targetErrors.Err()
+}
+
+// GenerateFS converts the SQL files of a file system in memory like GenerateInput, e.g. the files of an embed.FS or fstest.MapFS.
+// The input directory of the configuration is not used, the output files are located in the configured output directories but not written.
+//
+// Parameters:
+// - fsys: The file system containing the SQL files, see FindInputFilesFS.
+//
+// Return:
+// - []GeneratedFile: The files that would be written for the configured outputs.
+// - error: An error if the file system could not be read, otherwise the errors of Generate, see Generate.
+func (s2i *SQL2Interface) GenerateFS(fsys fs.FS) ([]GeneratedFile, error) {
+	files, err := FindInputFilesFS(fsys, s2i.Config.InputOptions())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s2i.Generate(files)
+}
@@ -0,0 +1,149 @@
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDDL = `-- shop schema
+CREATE TABLE users (id INT, email TEXT, created_at TIMESTAMP);
+CREATE INDEX users_email ON users (email);
+CREATE TABLE IF NOT EXISTS orders (
+    id INT NOT NULL, -- primary key; not reused
+    total DECIMAL
+);
+CREATE TABLE broken (id);
+`
+
+func TestParseDDL(t *testing.T) {
+	tables, err := ParseDDL(strings.NewReader(testDDL), DDLOptions{FileName: "schema.sql"})
+
+	var parseError ParseError
+	assert.ErrorAs(t, err, &parseError)
+	assert.Equal(t, "broken", parseError.Table)
+
+	assert.Len(t, tables, 2)
+	assert.Equal(t, SQL{FileName: "schema.sql", TableName: "orders", OriginalName: "orders", Columns: []Column{
+		{Name: "id", OriginalName: "id", Type: "int", Constraints: "not null", Comment: "primary key; not reused"},
+		{Name: "total", OriginalName: "total", Type: "decimal"},
+	}}, tables[1])
+}
+
+func TestParseDDLColumnTypes(t *testing.T) {
+	tables, err := ParseDDL(strings.NewReader(`CREATE TABLE products (
+    id INT,
+    name VARCHAR(255) NOT NULL, -- display name
+    price DECIMAL(10,2) DEFAULT 0.00,
+    tax DECIMAL (5, 2),
+    note TEXT DEFAULT 'a, b (c)',
+    PRIMARY KEY (id),
+    KEY idx_name (name)
+) ENGINE=InnoDB;`), DDLOptions{FileName: "schema.sql"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Column{
+		{Name: "id", OriginalName: "id", Type: "int"},
+		{Name: "name", OriginalName: "name", Type: "varchar(255)", Constraints: "not null", Comment: "display name"},
+		{Name: "price", OriginalName: "price", Type: "decimal(10,2)", Constraints: "default 0.00"},
+		{Name: "tax", OriginalName: "tax", Type: "decimal(5, 2)"},
+		{Name: "note", OriginalName: "note", Type: "text", Constraints: "default 'a, b (c)'"},
+	}, tables[0].Columns)
+
+	content, _ := Render(tables, "go", nil)
+	assert.Contains(t, string(content), "\tName string\r\n\tPrice float32\r\n\tTax float32\r\n")
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements, err := SplitStatements("CREATE TABLE a (id INT DEFAULT '--x;'); /* b; */ CREATE TABLE `c;` (id INT) -- d;\n;")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CREATE TABLE a (id INT DEFAULT '--x;')", "/* b; */ CREATE TABLE `c;` (id INT) -- d;"}, statements)
+
+	statements, err = SplitStatements("CREATE TABLE a (id INT);\nCREATE TABLE b (id INT DEFAULT 'x);")
+	assert.EqualError(t, err, "unterminated string starting in line 2")
+	assert.Equal(t, []string{"CREATE TABLE a (id INT)"}, statements)
+
+	_, err = ParseDDL(strings.NewReader("CREATE TEMPORARY TABLE a (id INT);\nCREAT TABLE b (id INT);\nCREATE TABLE c (id INT /* open"), DDLOptions{FileName: "schema.sql"})
+	assert.EqualError(t, err, "schema.sql: unsupported table statement 'CREATE TEMPORARY TABLE a (id INT)'\n"+
+		"schema.sql: unknown statement 'CREAT TABLE b (id INT)'\n"+
+		"schema.sql: unterminated comment starting in line 3")
+}
+
+func TestRender(t *testing.T) {
+	tables, _ := ParseDDL(strings.NewReader(testDDL), DDLOptions{})
+
+	content, err := Render(tables, "go", &Config{
+		Output:        OutputConfig{Go: &GoOutput{PackageName: "models"}},
+		IgnoreColumns: map[string][]string{"*": {"created_at"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, GeneratedHeader+"\n\npackage models\n\n\ntype Users struct {\n\tId int\r\n\tEmail string\r\n}\n\ntype Orders struct {\n\t// primary key; not reused\r\n\tId int\r\n\tTotal float32\r\n}", string(content))
+
+	content, err = Render(tables, "typescript", nil)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "interface Orders {\n\t/** primary key; not reused */\r\n\tId: Number, \r\n\tTotal: Number\r\n}")
+
+	_, err = Render(tables, "rust", nil)
+	assert.EqualError(t, err, "unknown target 'rust', expected typescript or go")
+}
+
+func TestGenerateFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"users.sql":            {Data: []byte("CREATE TABLE users (id INT)")},
+		"billing/invoices.sql": {Data: []byte("CREATE TABLE invoices (id INT)")},
+		".git/config.sql":      {Data: []byte("CREATE TABLE hidden (id INT)")},
+		"README.md":            {Data: []byte("# Schema")},
+	}
+
+	s2i := NewSQL2InterfaceFromConfig(&Config{Output: OutputConfig{TypeScript: &TypeScriptOutput{OutputDir: "out", OutputFile: "types.ts"}}})
+	files, err := s2i.GenerateFS(fsys)
+
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.Contains(t, files[0].Content, "interface Invoices")
+	assert.Contains(t, files[0].Content, "interface Users")
+	assert.NotContains(t, files[0].Content, "Hidden")
+}
+
+func TestMultiStatementFiles(t *testing.T) {
+	const dump = "DROP TABLE IF EXISTS orders;\nCREATE TABLE orders (id INT, total DECIMAL);\nCREATE TABLE order_items (id INT, order_id INT);\n"
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "sql")
+	assert.NoError(t, os.MkdirAll(input, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(input, "dump.sql"), []byte(dump), 0644))
+
+	conf := &Config{
+		Input: input,
+		Output: OutputConfig{
+			TypeScript: &TypeScriptOutput{OutputDir: filepath.Join(dir, "ts"), OutputFile: "types.ts"},
+			Go:         &GoOutput{OutputDir: filepath.Join(dir, "go"), OutputFile: "types.go", PackageName: "models"},
+		},
+	}
+	assert.NoError(t, NewSQL2InterfaceFromConfig(conf).Run())
+
+	content, readError := os.ReadFile(filepath.Join(dir, "go", "types.go"))
+	assert.NoError(t, readError)
+	assert.Contains(t, string(content), "type Orders struct")
+	assert.Contains(t, string(content), "type Order_items struct")
+	assert.NotContains(t, string(content), "Drop")
+
+	// files of a file system and scanned files are parsed the same way
+	files, err := NewSQL2InterfaceFromConfig(conf).GenerateFS(fstest.MapFS{"dump.sql": {Data: []byte(dump)}})
+	assert.NoError(t, err)
+	for _, file := range files {
+		if file.Target == "go" {
+			assert.Equal(t, string(content), file.Content)
+		}
+	}
+
+	scanned, err := ScanTables(input, InputOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, scanned, 2)
+	assert.Equal(t, "orders", scanned[0].TableName)
+	assert.Equal(t, "order_items", scanned[1].TableName)
+}
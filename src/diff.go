@@ -0,0 +1,149 @@
+package src
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around every change in a unified diff.
+const diffContextLines = 3
+
+// diffLine is a single line of a diff. Kind is ' ' for unchanged, '-' for removed and '+' for added lines.
+type diffLine struct {
+	Kind    byte
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+// UnifiedDiff creates a unified diff between two versions of a file.
+// It returns an empty string if both versions are equal.
+//
+// Parameters:
+// - oldName: The name of the old version shown in the "---" header.
+// - newName: The name of the new version shown in the "+++" header.
+// - oldContent: The content of the old version.
+// - newContent: The content of the new version.
+//
+// Return:
+// - string: The unified diff.
+func UnifiedDiff(oldName string, newName string, oldContent string, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+
+	lines := diffLines(splitLines(oldContent), splitLines(newContent))
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- %v\n+++ %v\n", oldName, newName)
+
+	for start := 0; start < len(lines); {
+		// find the next change
+		for start < len(lines) && lines[start].Kind == ' ' {
+			start++
+		}
+		if start == len(lines) {
+			break
+		}
+
+		// extend the hunk until there are more than two times the context lines without changes
+		hunkStart := max(start-diffContextLines, 0)
+		end := start
+		for unchanged := 0; end < len(lines) && unchanged <= 2*diffContextLines; end++ {
+			if lines[end].Kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		hunkEnd := end
+		for hunkEnd > start && lines[hunkEnd-1].Kind == ' ' {
+			hunkEnd--
+		}
+		hunkEnd = min(hunkEnd+diffContextLines, len(lines))
+
+		writeHunk(&builder, lines[hunkStart:hunkEnd])
+		start = hunkEnd
+	}
+
+	return builder.String()
+}
+
+// writeHunk writes a single hunk including its "@@" header.
+func writeHunk(builder *strings.Builder, hunk []diffLine) {
+	oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+
+	for _, line := range hunk {
+		if line.Kind != '+' {
+			if oldCount == 0 {
+				oldStart = line.OldLine
+			}
+			oldCount++
+		}
+		if line.Kind != '-' {
+			if newCount == 0 {
+				newStart = line.NewLine
+			}
+			newCount++
+		}
+	}
+
+	// empty ranges point at the line before the hunk
+	if oldCount == 0 {
+		oldStart = hunk[0].OldLine - 1
+	}
+	if newCount == 0 {
+		newStart = hunk[0].NewLine - 1
+	}
+
+	fmt.Fprintf(builder, "@@ -%v,%v +%v,%v @@\n", oldStart, oldCount, newStart, newCount)
+	for _, line := range hunk {
+		fmt.Fprintf(builder, "%c%v\n", line.Kind, line.Text)
+	}
+}
+
+// diffLines computes the line diff of two files using the longest common subsequence.
+func diffLines(oldLines []string, newLines []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			lines = append(lines, diffLine{Kind: ' ', Text: oldLines[i], OldLine: i + 1, NewLine: j + 1})
+			i++
+			j++
+		case i < len(oldLines) && (j == len(newLines) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{Kind: '-', Text: oldLines[i], OldLine: i + 1, NewLine: j + 1})
+			i++
+		default:
+			lines = append(lines, diffLine{Kind: '+', Text: newLines[j], OldLine: i + 1, NewLine: j + 1})
+			j++
+		}
+	}
+
+	return lines
+}
+
+// splitLines splits a file into lines without their trailing newline.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
@@ -0,0 +1,23 @@
+package src
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	assert.Equal(t, "", UnifiedDiff("a", "b", "same\n", "same\n"))
+
+	oldContent := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	newContent := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n"
+
+	expected := "--- old\n+++ new\n" +
+		"@@ -2,9 +2,10 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n 9\n 10\n+11\n"
+
+	assert.Equal(t, expected, UnifiedDiff("old", "new", oldContent, newContent))
+}
+
+func TestUnifiedDiffNewFile(t *testing.T) {
+	assert.Equal(t, "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n", UnifiedDiff("old", "new", "", "a\nb\n"))
+}
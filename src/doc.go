@@ -0,0 +1,6 @@
+// Package src implements the sql2interface command line tool: loading the configuration, walking the input directory,
+// converting the tables and writing the output files.
+//
+// Its API follows the needs of the command line tool and may change between releases.
+// Programs that convert SQL to code should import the packages schema and gen, which follow semantic versioning.
+package src
@@ -0,0 +1,99 @@
+package src
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotGenerated is the error of a WriteError if an existing output file would be overwritten that was not generated by sql2interface.
+var ErrNotGenerated = errors.New("the file was not generated by sql2interface and is not overwritten, use -force to overwrite it")
+
+// ParseError is a SQL file of the input directory that could not be read or parsed.
+type ParseError struct {
+	// File is the name of the file relative to the input directory, e.g. "billing/invoices.sql"
+	File string
+	// Table is the name of the table as written in the file, or an empty string if the table name was not found
+	Table string
+	Err   error
+}
+
+func (parseError ParseError) Error() string {
+	var location []string
+	if parseError.File != "" {
+		location = append(location, parseError.File)
+	}
+	if parseError.Table != "" {
+		location = append(location, "table "+parseError.Table)
+	}
+
+	return strings.Join(append(location, parseError.Err.Error()), ": ")
+}
+
+func (parseError ParseError) Unwrap() error {
+	return parseError.Err
+}
+
+// CombineError is a combined structure of combine_tables that could not be created for an output type.
+type CombineError struct {
+	// Structure is the name of the combined structure
+	Structure string
+	// Target is the output type, "typescript" or "go"
+	Target string
+	Err    error
+}
+
+func (combineError CombineError) Error() string {
+	return fmt.Sprintf("combined structure %v (%v): %v", combineError.Structure, combineError.Target, combineError.Err)
+}
+
+func (combineError CombineError) Unwrap() error {
+	return combineError.Err
+}
+
+// WriteError is an output file that could not be written.
+type WriteError struct {
+	Path string
+	Err  error
+}
+
+func (writeError WriteError) Error() string {
+	return fmt.Sprintf("error writing %v: %v", writeError.Path, writeError.Err)
+}
+
+func (writeError WriteError) Unwrap() error {
+	return writeError.Err
+}
+
+// ConversionErrors contains every problem found during a conversion so they can be reported together.
+// The single errors are ParseErrors, CombineErrors, WriteErrors or ConfigErrors and can be found with errors.As.
+type ConversionErrors []error
+
+func (conversionErrors ConversionErrors) Error() string {
+	var messages []string
+	for _, conversionError := range conversionErrors {
+		messages = append(messages, conversionError.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (conversionErrors ConversionErrors) Unwrap() []error {
+	return conversionErrors
+}
+
+// Add appends an error to the list. Nil errors are skipped and ConversionErrors are flattened.
+func (conversionErrors *ConversionErrors) Add(err error) {
+	if nested, isList := err.(ConversionErrors); isList {
+		*conversionErrors = append(*conversionErrors, nested...)
+	} else if err != nil {
+		*conversionErrors = append(*conversionErrors, err)
+	}
+}
+
+// Err returns the list as a single error, or nil if it is empty.
+func (conversionErrors ConversionErrors) Err() error {
+	if len(conversionErrors) == 0 {
+		return nil
+	}
+	return conversionErrors
+}
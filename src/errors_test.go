@@ -0,0 +1,60 @@
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertErrors(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "sql")
+	assert.NoError(t, os.MkdirAll(input, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(input, "users.sql"), []byte("CREATE TABLE users (id INT, email VARCHAR(255))"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(input, "orders.sql"), []byte("CREATE TABLE orders (id)"), 0644))
+	// the output directory of go is a file, so it cannot be written
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go"), nil, 0644))
+
+	s2i := NewSQL2InterfaceFromConfig(&Config{
+		Input: input,
+		Output: OutputConfig{
+			TypeScript: &TypeScriptOutput{OutputDir: filepath.Join(dir, "ts"), OutputFile: "types.ts"},
+			Go:         &GoOutput{OutputDir: filepath.Join(dir, "go"), OutputFile: "types.go", PackageName: "models"},
+		},
+		CombineTables: map[string]TableCombine{"all": {Name: "All", Tables: []string{"users", "missing"}, ConvertSingleTables: true}},
+	})
+
+	err := s2i.Run()
+
+	var conversionErrors ConversionErrors
+	assert.ErrorAs(t, err, &conversionErrors)
+	assert.Len(t, conversionErrors, 4)
+
+	var parseError ParseError
+	assert.ErrorAs(t, err, &parseError)
+	assert.Equal(t, "orders.sql", parseError.File)
+	assert.Equal(t, "orders", parseError.Table)
+
+	var combineError CombineError
+	assert.ErrorAs(t, err, &combineError)
+	assert.Equal(t, "All", combineError.Structure)
+
+	var writeError WriteError
+	assert.ErrorAs(t, err, &writeError)
+	assert.Equal(t, filepath.Join(dir, "go", "types.go"), writeError.Path)
+
+	// the typescript file is written although other files failed
+	content, readError := os.ReadFile(filepath.Join(dir, "ts", "types.ts"))
+	assert.NoError(t, readError)
+	assert.Contains(t, string(content), "interface Users")
+}
+
+func TestRunMissingInput(t *testing.T) {
+	s2i := NewSQL2InterfaceFromConfig(&Config{Input: filepath.Join(t.TempDir(), "missing")})
+
+	var configError ConfigError
+	assert.ErrorAs(t, s2i.Run(), &configError)
+	assert.Contains(t, configError.Message, "could not be read")
+}
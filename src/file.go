@@ -1,10 +1,15 @@
 package src
 
 import (
-	// "fmt"
+	"bytes"
+	"errors"
+	"fmt"
 	"gopkg.in/yaml.v3"
+	"io/fs"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // GetFiles retrieves a list of files  within a specified directory.
@@ -47,6 +52,10 @@ func GetFileContent(dir string, fileName string) (string, error) {
 }
 
 // SaveFile writes the provided file content to a specified file within a directory.
+// The content is written to a temporary file in the same directory first, which then replaces the file,
+// so the file is never left half written if the program stops while writing.
+// The mode of an existing file is kept, new files are created with the mode of os.WriteFile (0666 reduced by the umask).
+// A symlink is kept as well and the file it points to is replaced instead.
 //
 // Parameters:
 // - dir: The path to the directory where the file will be saved.
@@ -54,18 +63,87 @@ func GetFileContent(dir string, fileName string) (string, error) {
 // - fileContent: The content to be written to the file.
 //
 // Returns:
-// - An error if any occurred during file writing. If the function completes successfully, it returns nil.
+// - An error if any occurred during file writing, e.g. if the file is a symlink pointing to a missing file. If the function completes successfully, it returns nil.
 func SaveFile(dir string, fileName string, fileContent string) error {
 
 	filePath := filepath.Join(dir, fileName)
 
-	writeError := os.WriteFile(filePath, []byte(fileContent), 0644)
+	if resolvedPath, resolveError := filepath.EvalSymlinks(filePath); resolveError == nil {
+		filePath = resolvedPath
+	} else if _, lstatError := os.Lstat(filePath); lstatError == nil {
+		return resolveError
+	}
+
+	// new files are created with 0666 reduced by the umask like os.WriteFile does
+	info, statError := os.Stat(filePath)
+	tempFile, createError := createTempFile(filepath.Dir(filePath), filepath.Base(filePath), 0666)
+	if createError != nil {
+		return createError
+	}
+	// removing fails once the file was renamed, which is expected
+	defer os.Remove(tempFile.Name())
 
-	if writeError != nil {
+	_, writeError := tempFile.WriteString(fileContent)
+	closeError := tempFile.Close()
+	if writeError = errors.Join(writeError, closeError); writeError != nil {
 		return writeError
 	}
 
-	return nil
+	// the umask does not apply to the mode of an existing file
+	if statError == nil {
+		if chmodError := os.Chmod(tempFile.Name(), info.Mode().Perm()); chmodError != nil {
+			return chmodError
+		}
+	}
+
+	return os.Rename(tempFile.Name(), filePath)
+}
+
+// createTempFile creates a new hidden temporary file for a file in a directory like os.CreateTemp,
+// but with the given permissions reduced by the umask instead of 0600.
+func createTempFile(dir string, fileName string, perm os.FileMode) (*os.File, error) {
+	for attempt := 0; ; attempt++ {
+		path := filepath.Join(dir, fmt.Sprintf(".%v.%v.tmp", fileName, rand.Uint32()))
+		file, openError := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if !errors.Is(openError, fs.ErrExist) || attempt >= 100 {
+			return file, openError
+		}
+	}
+}
+
+// IsGeneratedFile reports whether a file starts with the generated code header (see GeneratedHeader),
+// i.e. whether the file was written by sql2interface and can be overwritten.
+// Like the convention of go generate, the header has to appear before the first line that is neither blank nor a comment,
+// so a file that merely mentions the header further down is not treated as generated.
+//
+// content: The content of the file.
+//
+// Returns:
+// - true if the generated code header is found in front of the code of the file.
+func IsGeneratedFile(content []byte) bool {
+	inBlockComment := false
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case inBlockComment:
+			_, _, closed := strings.Cut(line, "*/")
+			inBlockComment = !closed
+		case line == GeneratedHeader:
+			return true
+		case line == "" || strings.HasPrefix(line, "//"):
+		case strings.HasPrefix(line, "/*"):
+			_, rest, closed := strings.Cut(line[2:], "*/")
+			if closed && strings.TrimSpace(rest) != "" {
+				return false
+			}
+			inBlockComment = !closed
+		default:
+			return false
+		}
+	}
+	return false
 }
 
 // IsDir checks if the specified file path represents a directory.
@@ -90,19 +168,123 @@ func IsDir(filePath string) (bool, error) {
 /* YAML */
 
 type Config struct {
-	IgnoreFiles     []string                             `yaml:"ignore_files"`
-	IgnoreColumns   map[string][]string                  `yaml:"ignore_columns"`
-	CombineTables   map[string]TableCombine              `yaml:"combine_tables"`
-	Input           string                               `yaml:"input"`
-	Output          map[string]map[string]string         `yaml:"output"`
-	SingleFile      bool                                 `yaml:"single_file"`
-	ArbitraryFields map[string]map[string]ArbitraryField `yaml:"arbitrary_fields"`
+	IgnoreFiles       []string                             `yaml:"ignore_files"`
+	IgnoreColumns     map[string][]string                  `yaml:"ignore_columns"`
+	CombineTables     map[string]TableCombine              `yaml:"combine_tables"`
+	Input             string                               `yaml:"input"`
+	InputSchema       string                               `yaml:"input_schema"`
+	IncludeExtensions []string                             `yaml:"include_extensions"`
+	FollowSymlinks    bool                                 `yaml:"follow_symlinks"`
+	MirrorInputDirs   bool                                 `yaml:"mirror_input_dirs"`
+	Output            OutputConfig                         `yaml:"output"`
+	SingleFile        *bool                                `yaml:"single_file"`
+	KeepRegions       bool                                 `yaml:"keep_regions"`
+	ArbitraryFields   map[string]map[string]ArbitraryField `yaml:"arbitrary_fields"`
+	Naming            map[string]NamingConfig              `yaml:"naming"`
+	Profiles          map[string]yaml.Node                 `yaml:"profiles,omitempty"`
+	// Schema is the URL of the JSON Schema of the configuration used by editors, it is not used by sql2interface
+	Schema string `yaml:"$schema,omitempty"`
+
+	// file is the path of the loaded configuration file, positions maps keys like "output.go.output_dir" to their position in it
+	file      string
+	positions map[string]Position
+}
+
+// OutputConfig contains the settings of every output type. Output types that are nil are not generated.
+type OutputConfig struct {
+	TypeScript *TypeScriptOutput `yaml:"typescript"`
+	Go         *GoOutput         `yaml:"go"`
+}
+
+type TypeScriptOutput struct {
+	OutputDir   string `yaml:"output_dir"`
+	OutputFile  string `yaml:"output_file"`
+	ExportTypes bool   `yaml:"export_types"`
+	// FilePattern is the file name of every interface if single_file is false, DefaultTypeScriptFilePattern if empty
+	FilePattern string `yaml:"file_pattern"`
+}
+
+type GoOutput struct {
+	OutputDir   string `yaml:"output_dir"`
+	OutputFile  string `yaml:"output_file"`
+	PackageName string `yaml:"package_name"`
+	// ExportTypes is accepted for compatibility with older configurations. Go structs are always exported.
+	ExportTypes bool `yaml:"export_types"`
+	// FilePattern is the file name of every struct if single_file is false, DefaultGoFilePattern if empty
+	FilePattern string `yaml:"file_pattern"`
+}
+
+// Targets returns the names of all configured output types.
+func (output OutputConfig) Targets() []string {
+	var targets []string
+	if output.TypeScript != nil {
+		targets = append(targets, "typescript")
+	}
+	if output.Go != nil {
+		targets = append(targets, "go")
+	}
+	return targets
+}
+
+// Disable removes an output type from the configuration.
+func (output *OutputConfig) Disable(target string) {
+	switch target {
+	case "typescript":
+		output.TypeScript = nil
+	case "go":
+		output.Go = nil
+	}
+}
+
+// SetOutputDir sets the output directory of every configured output type.
+func (output *OutputConfig) SetOutputDir(dir string) {
+	if output.TypeScript != nil {
+		output.TypeScript.OutputDir = dir
+	}
+	if output.Go != nil {
+		output.Go.OutputDir = dir
+	}
+}
+
+// OutputDir returns the output directory of an output type or an empty string if the output type is not configured.
+func (output OutputConfig) OutputDir(target string) string {
+	switch {
+	case target == "typescript" && output.TypeScript != nil:
+		return output.TypeScript.OutputDir
+	case target == "go" && output.Go != nil:
+		return output.Go.OutputDir
+	}
+	return ""
+}
+
+// FilePattern returns the file name pattern of an output type used if single_file is false, see FileName.
+func (output OutputConfig) FilePattern(target string) string {
+	switch {
+	case target == "typescript" && output.TypeScript != nil:
+		return FirstNonEmpty(output.TypeScript.FilePattern, DefaultTypeScriptFilePattern)
+	case target == "go" && output.Go != nil:
+		return FirstNonEmpty(output.Go.FilePattern, DefaultGoFilePattern)
+	}
+	return ""
+}
+
+// OutputFile returns the output file of an output type or an empty string if the output type is not configured.
+// The output file is only used if single_file is true.
+func (output OutputConfig) OutputFile(target string) string {
+	switch {
+	case target == "typescript" && output.TypeScript != nil:
+		return output.TypeScript.OutputFile
+	case target == "go" && output.Go != nil:
+		return output.Go.OutputFile
+	}
+	return ""
 }
 
 type TableCombine struct {
 	Name                string   `yaml:"name"`
 	Tables              []string `yaml:"tables"`
 	ConvertSingleTables bool     `yaml:"convert_single_tables"`
+	OnCollision         string   `yaml:"on_collision"`
 }
 
 type Field struct {
@@ -111,19 +293,61 @@ type Field struct {
 }
 
 type ArbitraryField struct {
-	Name   string `yaml:"name"`
-	TypeGo string `yaml:"type_go"`
-	TypeTs string `yaml:"type_ts"`
+	Name     string            `yaml:"name"`
+	TypeGo   string            `yaml:"type_go"`
+	TypeTs   string            `yaml:"type_ts"`
+	Position string            `yaml:"position"`
+	Tags     map[string]string `yaml:"tags"`
+	Optional bool              `yaml:"optional"`
+	Comment  string            `yaml:"comment"`
 }
 
-// LoadConfig reads a YAML configuration file and unmarshals its content into a Config struct.
+type NamingConfig struct {
+	Types            string            `yaml:"types"`
+	Fields           string            `yaml:"fields"`
+	JSONTags         string            `yaml:"json_tags"`
+	Initialisms      bool              `yaml:"initialisms"`
+	ExtraInitialisms []string          `yaml:"extra_initialisms"`
+	Singularize      bool              `yaml:"singularize"`
+	TypePrefix       string            `yaml:"type_prefix"`
+	TypeSuffix       string            `yaml:"type_suffix"`
+	FieldPrefix      string            `yaml:"field_prefix"`
+	FieldSuffix      string            `yaml:"field_suffix"`
+	Rename           map[string]string `yaml:"rename"`
+	Escape           string            `yaml:"escape"`
+	EscapePrefix     string            `yaml:"escape_prefix"`
+	EscapeSuffix     string            `yaml:"escape_suffix"`
+}
+
+// LoadConfig reads a YAML, JSON or TOML configuration file and decodes its content strictly into a Config struct.
+// The format is detected by the extension of the file, see ConfigFormat.
+// Unknown keys and values of the wrong type are reported together with their line and column.
+// The semantic validation of the configuration is done separately by Config.Validate.
 //
-// filePath: The path to the YAML configuration file.
+// filePath: The path to the configuration file.
 //
 // Returns:
-// - A pointer to a Config struct containing the unmarshalled configuration data.
-// - An error if any occurred during file reading or unmarshalling.
+//   - A pointer to a Config struct containing the unmarshalled configuration data.
+//   - An error if any occurred during file reading or decoding. Decoding problems are returned as ConfigErrors
+//     together with the partially decoded configuration (see DecodeConfig).
 func LoadConfig(filePath string) (*Config, error) {
+	return LoadConfigProfile(filePath, "")
+}
+
+// LoadConfigProfile reads a configuration file like LoadConfig and applies a profile, the S2I_* environment variables
+// and the environment of go generate (see ApplyGoGenerate).
+// The profile is read from the profiles section of the file and from the overlay file s2iconfig.<profile>.yaml next to it.
+// Relative paths are resolved against the directory of the configuration file, paths from environment variables
+// against the working directory.
+//
+// filePath: The path to the configuration file.
+// profile: The name of the profile, or an empty string to load the configuration without a profile.
+//
+// Returns:
+//   - A pointer to a Config struct containing the unmarshalled configuration data.
+//   - An error if any occurred during file reading or decoding. Decoding problems are returned as ConfigErrors
+//     together with the partially decoded configuration (see DecodeConfig).
+func LoadConfigProfile(filePath string, profile string) (*Config, error) {
 
 	fileContent, readFileError := os.ReadFile(filePath)
 
@@ -131,13 +355,293 @@ func LoadConfig(filePath string) (*Config, error) {
 		return nil, readFileError
 	}
 
-	var conf Config
+	var overlay *ConfigOverlay
+	if profile != "" {
+		overlayPath := ProfileOverlayPath(filePath, profile)
+		overlayContent, overlayError := os.ReadFile(overlayPath)
+
+		if overlayError == nil {
+			overlay = &ConfigOverlay{File: overlayPath, Content: overlayContent}
+		} else if !errors.Is(overlayError, os.ErrNotExist) {
+			return nil, overlayError
+		}
+	}
 
-	unmarshalError := yaml.Unmarshal(fileContent, &conf)
+	conf, decodeError := DecodeConfigProfile(filePath, fileContent, profile, overlay)
 
-	if unmarshalError != nil {
-		return nil, unmarshalError
+	if conf != nil {
+		if resolveError := conf.ResolvePaths(filepath.Dir(filePath)); resolveError != nil {
+			return nil, resolveError
+		}
+
+		if envError := ApplyEnvOverrides(conf, os.Environ()); envError != nil {
+			return nil, envError
+		}
+
+		if _, goGenerateError := ApplyGoGenerate(conf, os.Environ()); goGenerateError != nil {
+			return nil, goGenerateError
+		}
 	}
 
-	return &conf, nil
+	return conf, decodeError
+}
+
+// ResolvePaths expands the input directory, the input schema and the output directories of the configuration with ExpandPath,
+// so relative paths are resolved against the given base directory instead of the working directory.
+//
+// baseDir: The directory relative paths are resolved against, usually the directory of the configuration file.
+//
+// Returns:
+// - An error if the home directory is needed but cannot be determined.
+func (conf *Config) ResolvePaths(baseDir string) error {
+	paths := []*string{&conf.Input, &conf.InputSchema}
+	if conf.Output.TypeScript != nil {
+		paths = append(paths, &conf.Output.TypeScript.OutputDir)
+	}
+	if conf.Output.Go != nil {
+		paths = append(paths, &conf.Output.Go.OutputDir)
+	}
+
+	for _, path := range paths {
+		if *path == "" {
+			continue
+		}
+
+		expanded, expandError := ExpandPath(*path, baseDir)
+		if expandError != nil {
+			return expandError
+		}
+		*path = expanded
+	}
+
+	return nil
+}
+
+// ApplyGoGenerate fills the Go output with the environment set by go generate. The directory of $GOFILE becomes the output_dir
+// unless it is configured, $GOPACKAGE becomes the package_name unless it is configured and the files are written into the directory of $GOFILE,
+// because the package of another directory is unknown. The Go output is not enabled by it.
+// Nothing is changed outside of go generate, which is detected by $GOPACKAGE.
+//
+// Parameters:
+// - conf: The configuration to be filled, its paths already resolved (see ResolvePaths).
+// - environ: The environment in the format of os.Environ.
+//
+// Return:
+// - bool: Whether the program runs within go generate.
+// - error: An error if the directory of $GOFILE or the output directory cannot be determined.
+func ApplyGoGenerate(conf *Config, environ []string) (bool, error) {
+	variables := make(map[string]string)
+	for _, variable := range environ {
+		if name, value, found := strings.Cut(variable, "="); found {
+			variables[name] = value
+		}
+	}
+
+	goPackage := variables["GOPACKAGE"]
+	if goPackage == "" {
+		return false, nil
+	}
+
+	goOutput := conf.Output.Go
+	if goOutput == nil || variables["GOFILE"] == "" {
+		return true, nil
+	}
+
+	// go generate runs in the directory of $GOFILE, which only contains the file name
+	fileDir, absError := filepath.Abs(filepath.Dir(variables["GOFILE"]))
+	if absError != nil {
+		return true, absError
+	}
+
+	if goOutput.OutputDir == "" {
+		goOutput.OutputDir = fileDir
+	}
+
+	outputDir, absError := filepath.Abs(goOutput.OutputDir)
+	if absError != nil {
+		return true, absError
+	}
+
+	if goOutput.PackageName == "" && outputDir == fileDir {
+		goOutput.PackageName = goPackage
+	}
+
+	return true, nil
+}
+
+// ExpandPath expands environment variables written as ${NAME} or $NAME and a leading ~ for the home directory.
+// If the result is a relative path, it is resolved against the given base directory.
+//
+// path: The path to be expanded.
+// baseDir: The directory relative paths are resolved against.
+//
+// Returns:
+// - The expanded path.
+// - An error if the home directory is needed but cannot be determined.
+func ExpandPath(path string, baseDir string) (string, error) {
+	path = os.ExpandEnv(path)
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		home, homeError := os.UserHomeDir()
+		if homeError != nil {
+			return "", homeError
+		}
+		path = filepath.Join(home, path[1:])
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	return path, nil
+}
+
+// NewSQL2InterfaceFromConfig initializes a new SQL2Interface instance with an already loaded configuration.
+// It loads the combiner settings and returns a pointer to the new instance.
+//
+// conf: The configuration to be used.
+//
+// Returns: A pointer to a new SQL2Interface instance.
+func NewSQL2InterfaceFromConfig(conf *Config) *SQL2Interface {
+	s2i := &SQL2Interface{Config: conf}
+	s2i.LoadCombiner()
+	return s2i
+}
+
+// ApplyConfigOverrides overrides single configuration values with values in the format "key=value".
+// Nested keys are separated by dots, e.g. "output.go.package_name=models". Keys that contain dots themselves
+// (like file names) can be written in square brackets, e.g. "ignore_columns[users.sql]=[created_at]".
+// Values are parsed as YAML, so lists and booleans can be overridden as well.
+//
+// conf: The configuration to be modified.
+// overrides: The overrides in the format "key=value".
+//
+// Returns:
+// - An error if an override is malformed or does not fit the configuration.
+func ApplyConfigOverrides(conf *Config, overrides []string) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	content, marshalError := yaml.Marshal(conf)
+	if marshalError != nil {
+		return marshalError
+	}
+
+	values := make(map[string]interface{})
+	if unmarshalError := yaml.Unmarshal(content, &values); unmarshalError != nil {
+		return unmarshalError
+	}
+
+	for _, override := range overrides {
+		key, rawValue, found := strings.Cut(override, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid override '%v', expected key=value", override)
+		}
+
+		var value interface{}
+		if unmarshalError := yaml.Unmarshal([]byte(rawValue), &value); unmarshalError != nil {
+			return fmt.Errorf("invalid value in override '%v': %w", override, unmarshalError)
+		}
+
+		if setError := SetNestedValue(values, SplitConfigKey(key), value); setError != nil {
+			return fmt.Errorf("invalid override '%v': %w", override, setError)
+		}
+	}
+
+	content, marshalError = yaml.Marshal(values)
+	if marshalError != nil {
+		return marshalError
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	var overridden Config
+	if decodeError := decoder.Decode(&overridden); decodeError != nil {
+		// the line numbers refer to the internally marshalled configuration and are dropped
+		var typeError *yaml.TypeError
+		if errors.As(decodeError, &typeError) {
+			messages := make([]string, len(typeError.Errors))
+			for i, message := range typeError.Errors {
+				messages[i] = yamlLinePattern.ReplaceAllString(message, "")
+			}
+			return fmt.Errorf("invalid override: %v", strings.Join(messages, "; "))
+		}
+		return fmt.Errorf("invalid override: %w", decodeError)
+	}
+
+	overridden.file = conf.file
+	overridden.positions = conf.positions
+	*conf = overridden
+	return nil
+}
+
+// SplitConfigKey splits a configuration key like "output.go.output_dir" or "ignore_columns[users.sql]" into its segments.
+func SplitConfigKey(key string) []string {
+	var segments []string
+	current := ""
+	inBrackets := false
+
+	for _, r := range key {
+		switch {
+		case r == '[' && !inBrackets:
+			if current != "" {
+				segments = append(segments, current)
+			}
+			current = ""
+			inBrackets = true
+		case r == ']' && inBrackets:
+			segments = append(segments, current)
+			current = ""
+			inBrackets = false
+		case r == '.' && !inBrackets:
+			if current != "" {
+				segments = append(segments, current)
+			}
+			current = ""
+		default:
+			current += string(r)
+		}
+	}
+
+	if current != "" {
+		segments = append(segments, current)
+	}
+
+	return segments
+}
+
+// SetNestedValue sets a value in nested maps, creating missing maps along the way.
+//
+// values: The map to be modified.
+// path: The keys leading to the value.
+// value: The value to be set.
+//
+// Returns:
+// - An error if the path is empty or an intermediate value is not a map.
+func SetNestedValue(values map[string]interface{}, path []string, value interface{}) error {
+	if len(path) == 0 {
+		return errors.New("empty key")
+	}
+
+	current := values
+	for _, segment := range path[:len(path)-1] {
+		next, exists := current[segment]
+		if !exists || next == nil {
+			created := make(map[string]interface{})
+			current[segment] = created
+			current = created
+			continue
+		}
+
+		nextMap, isMap := next.(map[string]interface{})
+		if !isMap {
+			return fmt.Errorf("%v is not a section", segment)
+		}
+		current = nextMap
+	}
+
+	current[path[len(path)-1]] = value
+	return nil
 }
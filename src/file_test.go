@@ -0,0 +1,106 @@
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitConfigKey(t *testing.T) {
+	assert.Equal(t, []string{"output", "go", "package_name"}, SplitConfigKey("output.go.package_name"))
+	assert.Equal(t, []string{"ignore_columns", "users.sql"}, SplitConfigKey("ignore_columns[users.sql]"))
+}
+
+func TestApplyConfigOverrides(t *testing.T) {
+	conf := &Config{Input: "./sql", Output: OutputConfig{Go: &GoOutput{PackageName: "main"}}}
+
+	err := ApplyConfigOverrides(conf, []string{"output.go.package_name=models", "ignore_columns[users.sql]=[created_at, updated_at]", "single_file=false"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "./sql", conf.Input)
+	assert.Equal(t, "models", conf.Output.Go.PackageName)
+	assert.Equal(t, []string{"created_at", "updated_at"}, conf.IgnoreColumns["users.sql"])
+	assert.False(t, conf.WritesSingleFile())
+
+	assert.Error(t, ApplyConfigOverrides(conf, []string{"input"}))
+	assert.Error(t, ApplyConfigOverrides(conf, []string{"input.nested=x"}))
+	assert.Error(t, ApplyConfigOverrides(conf, []string{"output.go.ouput_dir=x"}))
+}
+
+func TestApplyGoGenerate(t *testing.T) {
+	environ := []string{"GOPACKAGE=models", "GOFILE=models.go", "GOLINE=3"}
+	workingDir, _ := os.Getwd()
+
+	conf := &Config{Output: OutputConfig{Go: &GoOutput{OutputFile: "models_gen.go"}, TypeScript: &TypeScriptOutput{OutputDir: "./ts"}}}
+	goGenerate, err := ApplyGoGenerate(conf, environ)
+	assert.NoError(t, err)
+	assert.True(t, goGenerate)
+	assert.Equal(t, &GoOutput{OutputDir: workingDir, OutputFile: "models_gen.go", PackageName: "models"}, conf.Output.Go)
+
+	// configured values are kept
+	conf = &Config{Output: OutputConfig{Go: &GoOutput{OutputDir: "./out", PackageName: "dto"}}}
+	ApplyGoGenerate(conf, environ)
+	assert.Equal(t, &GoOutput{OutputDir: "./out", PackageName: "dto"}, conf.Output.Go)
+
+	// the package is only known for the directory of $GOFILE
+	conf = &Config{Output: OutputConfig{Go: &GoOutput{OutputDir: "./out"}}}
+	ApplyGoGenerate(conf, environ)
+	assert.Equal(t, &GoOutput{OutputDir: "./out"}, conf.Output.Go)
+
+	conf = &Config{Output: OutputConfig{Go: &GoOutput{OutputDir: workingDir + "/."}}}
+	ApplyGoGenerate(conf, environ)
+	assert.Equal(t, "models", conf.Output.Go.PackageName)
+
+	goGenerate, _ = ApplyGoGenerate(conf, []string{"GOFILE=models.go"})
+	assert.False(t, goGenerate)
+}
+
+func TestExpandPath(t *testing.T) {
+	t.Setenv("S2I_TEST_SCHEMA", "schema")
+	t.Setenv("HOME", "/home/s2i")
+
+	expanded, err := ExpandPath("./sql/${S2I_TEST_SCHEMA}", "/repo/config")
+	assert.NoError(t, err)
+	assert.Equal(t, "/repo/config/sql/schema", expanded)
+
+	expanded, _ = ExpandPath("~/sql", "/repo/config")
+	assert.Equal(t, "/home/s2i/sql", expanded)
+
+	expanded, _ = ExpandPath("/abs/$S2I_TEST_SCHEMA", "/repo/config")
+	assert.Equal(t, "/abs/schema", expanded)
+}
+
+func TestIsGeneratedFile(t *testing.T) {
+	assert.True(t, IsGeneratedFile([]byte(GeneratedHeader+"\n\npackage models\n")))
+	assert.True(t, IsGeneratedFile([]byte("//go:build tools\n\n/* models\n */\n"+GeneratedHeader+"\r\npackage models\n")))
+	assert.False(t, IsGeneratedFile([]byte("package models\n\n"+GeneratedHeader+"\n")))
+	assert.False(t, IsGeneratedFile([]byte("package models\n\nconst header = `\n"+GeneratedHeader+"\n`\n")))
+}
+
+func TestSaveFile(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "models.go")
+	assert.NoError(t, os.WriteFile(target, []byte("old"), 0600))
+
+	// the mode of an existing file is kept
+	assert.NoError(t, SaveFile(dir, "models.go", "new"))
+	info, _ := os.Stat(target)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// symlinks are kept and the file they point to is written
+	assert.NoError(t, os.Symlink(target, filepath.Join(dir, "link.go")))
+	assert.NoError(t, SaveFile(dir, "link.go", "linked"))
+	linkInfo, _ := os.Lstat(filepath.Join(dir, "link.go"))
+	assert.Equal(t, os.ModeSymlink, linkInfo.Mode().Type())
+	content, _ := os.ReadFile(target)
+	assert.Equal(t, "linked", string(content))
+
+	// new files get the same mode as files written by os.WriteFile, which applies the umask
+	assert.NoError(t, SaveFile(dir, "new.go", "created"))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "reference"), nil, 0666))
+	info, _ = os.Stat(filepath.Join(dir, "new.go"))
+	reference, _ := os.Stat(filepath.Join(dir, "reference"))
+	assert.Equal(t, reference.Mode().Perm(), info.Mode().Perm())
+}
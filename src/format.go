@@ -0,0 +1,235 @@
+package src
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+)
+
+// ConfigFileNames are the names of the configuration files that are searched for if no configuration file is given, in this order.
+var ConfigFileNames = []string{"s2iconfig.yaml", "s2iconfig.yml", "s2iconfig.json", "s2iconfig.toml"}
+
+// ConfigFormat returns the format of a configuration file detected by its extension.
+// Files with an unknown extension are read as YAML.
+//
+// fileName: The name of the configuration file.
+//
+// Returns:
+// - The format of the file: FormatYAML, FormatJSON or FormatTOML.
+func ConfigFormat(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	}
+	return FormatYAML
+}
+
+// FindConfigFile returns the path of the first configuration file of ConfigFileNames that exists in a directory.
+// If none of them exists, the path of s2iconfig.yaml is returned.
+//
+// dir: The directory that is searched.
+//
+// Returns:
+// - The path of the configuration file.
+func FindConfigFile(dir string) string {
+	for _, name := range ConfigFileNames {
+		path := filepath.Join(dir, name)
+		if _, statError := os.Stat(path); statError == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, ConfigFileNames[0])
+}
+
+// ParseConfigNode parses the content of a YAML, JSON or TOML configuration file into a yaml node, keeping the line and column of every value.
+// The format is detected by the extension of the file name, see ConfigFormat. An empty file results in an empty mapping.
+//
+// fileName: The name of the configuration file used to detect the format and in error messages.
+// content: The content of the configuration file.
+//
+// Returns:
+// - The root node of the configuration.
+// - A ConfigError if the content is not valid in the format of the file.
+func ParseConfigNode(fileName string, content []byte) (*yaml.Node, error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}, nil
+	}
+
+	switch ConfigFormat(fileName) {
+	case FormatJSON:
+		return parseJSONNode(fileName, content)
+	case FormatTOML:
+		return ParseTOML(fileName, content)
+	}
+
+	var root yaml.Node
+	if parseError := yaml.Unmarshal(content, &root); parseError != nil {
+		return nil, yamlError(fileName, parseError.Error())
+	}
+	if len(root.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}, nil
+	}
+	return root.Content[0], nil
+}
+
+// sourcePositions converts byte offsets of a file into lines and columns.
+type sourcePositions []int
+
+// newSourcePositions records the offsets at which the lines of a file start.
+func newSourcePositions(content []byte) sourcePositions {
+	lineStarts := sourcePositions{0}
+	for offset, char := range content {
+		if char == '\n' {
+			lineStarts = append(lineStarts, offset+1)
+		}
+	}
+	return lineStarts
+}
+
+// at returns the line and column of a byte offset, both starting at 1.
+func (lineStarts sourcePositions) at(offset int) (int, int) {
+	line := sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset })
+	return line, offset - lineStarts[line-1] + 1
+}
+
+// jsonParser converts the tokens of encoding/json into yaml nodes with the position of every token.
+type jsonParser struct {
+	fileName  string
+	content   []byte
+	decoder   *json.Decoder
+	positions sourcePositions
+}
+
+// parseJSONNode parses a JSON configuration file into a yaml node.
+func parseJSONNode(fileName string, content []byte) (*yaml.Node, error) {
+	parser := &jsonParser{
+		fileName:  fileName,
+		content:   content,
+		decoder:   json.NewDecoder(bytes.NewReader(content)),
+		positions: newSourcePositions(content),
+	}
+	parser.decoder.UseNumber()
+
+	node, parseError := parser.value()
+	if parseError != nil {
+		return nil, parseError
+	}
+
+	_, offset, tokenError := parser.token()
+	if tokenError == nil {
+		return nil, parser.errorAt(offset, "unexpected content after the configuration")
+	}
+	if !errors.Is(tokenError, io.EOF) {
+		return nil, tokenError
+	}
+
+	return node, nil
+}
+
+// token reads the next token and returns the offset it starts at.
+func (parser *jsonParser) token() (json.Token, int, error) {
+	offset := int(parser.decoder.InputOffset())
+	for offset < len(parser.content) && strings.ContainsRune(" \t\r\n,:", rune(parser.content[offset])) {
+		offset++
+	}
+
+	token, tokenError := parser.decoder.Token()
+	if tokenError != nil && !errors.Is(tokenError, io.EOF) {
+		var syntaxError *json.SyntaxError
+		if errors.As(tokenError, &syntaxError) {
+			return nil, offset, parser.errorAt(int(syntaxError.Offset)-1, syntaxError.Error())
+		}
+		return nil, offset, parser.errorAt(len(parser.content), strings.TrimPrefix(tokenError.Error(), "json: "))
+	}
+
+	return token, offset, tokenError
+}
+
+// value reads the next JSON value including all nested values.
+func (parser *jsonParser) value() (*yaml.Node, error) {
+	token, offset, tokenError := parser.token()
+	if errors.Is(tokenError, io.EOF) {
+		return nil, parser.errorAt(len(parser.content), "unexpected end of JSON input")
+	}
+	if tokenError != nil {
+		return nil, tokenError
+	}
+
+	line, column := parser.positions.at(offset)
+	node := &yaml.Node{Kind: yaml.ScalarNode, Line: line, Column: column}
+
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '[' {
+			node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+			for parser.decoder.More() {
+				item, itemError := parser.value()
+				if itemError != nil {
+					return nil, itemError
+				}
+				node.Content = append(node.Content, item)
+			}
+		} else {
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+			keys := make(map[string]bool)
+			for parser.decoder.More() {
+				key, keyError := parser.value()
+				if keyError != nil {
+					return nil, keyError
+				}
+				if keys[key.Value] {
+					return nil, ConfigError{File: parser.fileName, Line: key.Line, Column: key.Column, Message: fmt.Sprintf("duplicate key '%v'", key.Value)}
+				}
+				keys[key.Value] = true
+
+				item, itemError := parser.value()
+				if itemError != nil {
+					return nil, itemError
+				}
+				node.Content = append(node.Content, key, item)
+			}
+		}
+
+		// closing delimiter
+		if _, _, tokenError := parser.token(); errors.Is(tokenError, io.EOF) {
+			return nil, parser.errorAt(len(parser.content), "unexpected end of JSON input")
+		} else if tokenError != nil {
+			return nil, tokenError
+		}
+	case string:
+		node.Tag, node.Value, node.Style = "!!str", value, yaml.DoubleQuotedStyle
+	case json.Number:
+		node.Tag, node.Value = "!!int", value.String()
+		if strings.ContainsAny(node.Value, ".eE") {
+			node.Tag = "!!float"
+		}
+	case bool:
+		node.Tag, node.Value = "!!bool", fmt.Sprint(value)
+	case nil:
+		node.Tag, node.Value = "!!null", "null"
+	}
+
+	return node, nil
+}
+
+// errorAt creates a ConfigError at a byte offset of the file.
+func (parser *jsonParser) errorAt(offset int, message string) ConfigError {
+	line, column := parser.positions.at(max(min(offset, len(parser.content)-1), 0))
+	return ConfigError{File: parser.fileName, Line: line, Column: column, Message: message}
+}
@@ -0,0 +1,148 @@
+package src
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const formatTestYAML = `input: ./sql
+output:
+  go:
+    output_dir: ./out
+    output_file: types.go
+    package_name: models
+ignore_columns:
+  users.sql: [created_at, updated_at]
+combine_tables:
+  Products:
+    name: Products
+    tables: [product, "product_price*"]
+    on_collision: keep_first
+naming:
+  go:
+    initialisms: true
+    rename:
+      users.pw_hash: "PasswordHash"
+`
+
+func TestDecodeConfigFormats(t *testing.T) {
+	expected, err := DecodeConfig("s2iconfig.yaml", []byte(formatTestYAML))
+	assert.NoError(t, err)
+
+	jsonContent := `{
+	"$schema": "./s2iconfig.schema.json",
+	"input": "./sql",
+	"output": {"go": {"output_dir": "./out", "output_file": "types.go", "package_name": "models"}},
+	"ignore_columns": {"users.sql": ["created_at", "updated_at"]},
+	"combine_tables": {"Products": {"name": "Products", "tables": ["product", "product_price*"], "on_collision": "keep_first"}},
+	"naming": {"go": {"initialisms": true, "rename": {"users.pw_hash": "Password\u0048ash"}}}
+}`
+	tomlContent := `input = "./sql" # comment
+ignore_columns."users.sql" = ["created_at", 'updated_at']
+
+[output.go]
+output_dir = "./out"
+output_file = "types.go"
+package_name = """
+models"""
+
+[combine_tables.Products]
+name = "Products"
+tables = [
+  "product",
+  "product_price*", # trailing comma
+]
+on_collision = "keep_first"
+
+[naming.go]
+initialisms = true
+rename = { "users.pw_hash" = "PasswordHash" }
+`
+
+	for fileName, content := range map[string]string{"s2iconfig.json": jsonContent, "s2iconfig.toml": tomlContent} {
+		conf, err := DecodeConfig(fileName, []byte(content))
+		if assert.NoError(t, err, fileName) {
+			conf.Schema = ""
+			assert.Equal(t, expected.Output, conf.Output, fileName)
+			assert.Equal(t, expected.IgnoreColumns, conf.IgnoreColumns, fileName)
+			assert.Equal(t, expected.CombineTables, conf.CombineTables, fileName)
+			assert.Equal(t, expected.Naming, conf.Naming, fileName)
+			assert.Equal(t, expected.Input, conf.Input, fileName)
+		}
+	}
+}
+
+func TestDecodeConfigFormatErrors(t *testing.T) {
+	tests := map[string]struct {
+		content  string
+		expected string
+	}{
+		"s2iconfig.json": {"{\n  \"input\": \"./sql\",\n  \"ouput\": {}\n}", "s2iconfig.json:3:3: unknown key 'ouput', did you mean 'output'?"},
+		"broken.json":    {"{\n  \"input\": \"./sql\"\n  \"output\": {}\n}", "broken.json:3:3: invalid character '\"' after object key:value pair"},
+		"s2iconfig.toml": {"input = \"./sql\"\n\n[output.go]\nouput_dir = \"./out\"\n", "s2iconfig.toml:4:1: unknown key 'ouput_dir' in output.go, did you mean 'output_dir'?"},
+		"types.toml":     {"single_file = \"true\"\n", "types.toml:1:15: single_file must be a bool, got the string \"true\""},
+		"yes.toml":       {"[output.typescript]\nexport_types = \"yes\"\n", "yes.toml:2:16: output.typescript.export_types must be a bool, got the string \"yes\""},
+		"yes.json":       {"{\n  \"output\": {\"typescript\": {\"export_types\": \"yes\"}}\n}", "yes.json:2:45: output.typescript.export_types must be a bool, got the string \"yes\""},
+		"twice.toml":     {"input = \"./sql\"\ninput = \"./other\"\n", "twice.toml:2:1: key input is already defined"},
+		"string.toml":    {"input = \"./sql\n", "string.toml:1:15: basic strings cannot have new lines"},
+	}
+
+	for fileName, test := range tests {
+		_, err := DecodeConfig(fileName, []byte(test.content))
+		assert.EqualError(t, err, test.expected, fileName)
+	}
+}
+
+func TestParseTOMLValues(t *testing.T) {
+	node, err := ParseTOML("values.toml", []byte("a = 0x1F\nb = 1_000\nc = -1.5e3\nd = 'C:\\path'\ne = \"tab\\tend\"\nh = -inf\n[[f]]\ng = 1\n[[f]]\ng = 2\n"))
+	assert.NoError(t, err)
+
+	var values map[string]interface{}
+	assert.NoError(t, node.Decode(&values))
+	assert.Equal(t, map[string]interface{}{
+		"a": 31, "b": 1000, "c": -1500.0, "d": `C:\path`, "e": "tab\tend", "h": math.Inf(-1),
+		"f": []interface{}{map[string]interface{}{"g": 1}, map[string]interface{}{"g": 2}},
+	}, values)
+
+	_, err = ParseTOML("values.toml", []byte("[a]\nb = 1\n[a]\nc = 2\n"))
+	assert.EqualError(t, err, "values.toml:3:2: table a already exists")
+}
+
+// TestConfigSchema makes sure the published JSON Schema contains every key of the configuration.
+func TestConfigSchema(t *testing.T) {
+	content, err := os.ReadFile("../s2iconfig.schema.json")
+	assert.NoError(t, err)
+
+	var schema map[string]interface{}
+	assert.NoError(t, json.Unmarshal(content, &schema))
+
+	definitions := schema["definitions"].(map[string]interface{})
+	objects := map[string]reflect.Type{
+		"":                 reflect.TypeOf(Config{}),
+		"typescriptOutput": reflect.TypeOf(TypeScriptOutput{}),
+		"goOutput":         reflect.TypeOf(GoOutput{}),
+		"tableCombine":     reflect.TypeOf(TableCombine{}),
+		"arbitraryField":   reflect.TypeOf(ArbitraryField{}),
+		"naming":           reflect.TypeOf(NamingConfig{}),
+	}
+
+	for name, objectType := range objects {
+		object := schema
+		if name != "" {
+			object = definitions[name].(map[string]interface{})
+		}
+		properties := object["properties"].(map[string]interface{})
+
+		for i := 0; i < objectType.NumField(); i++ {
+			if key := strings.Split(objectType.Field(i).Tag.Get("yaml"), ",")[0]; key != "" {
+				assert.Contains(t, SortedKeys(properties), key, "schema of %v", objectType.Name())
+			}
+		}
+	}
+}
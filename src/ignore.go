@@ -2,46 +2,183 @@ package src
 
 import (
 	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
 	"strings"
 )
 
-// IsFileIgnored checks if a given file name is present in a list of ignored files.//+
-// It returns true if the file is ignored, and false otherwise.//+
-// //+
-// Parameters://+
-// - fileName: The name of the file to check.//+
-// - ignoreFiles: A slice of strings containing the names of the files to be ignored.//+
-// //+
-// Return://+
-// - A boolean value indicating whether the file is ignored. If true, the file is ignored.//+
-func IsFileIgnored(fileName string, ignoreFiles []string) bool {
-	for _, ignoreFile := range ignoreFiles {
-		if strings.EqualFold(ignoreFile, fileName) {
-			fmt.Printf("  => file %v will be ignored\n", fileName)
-			return true
+// IsFileIgnored checks if a given file name is ignored by the rules of ignore_files, see MatchIgnoreRules.
+// The decision is logged together with the rule that triggered it.
+//
+// Parameters:
+// - logger: The logger of the decision, nothing is logged if it is nil.
+// - fileName: The name of the file to check.
+// - ignoreFiles: The rules of ignore_files.
+//
+// Return:
+// - A boolean value indicating whether the file is ignored. If true, the file is ignored.
+func IsFileIgnored(logger *slog.Logger, fileName string, ignoreFiles []string) bool {
+	rule, ignored := MatchIgnoreRules(fileName, ignoreFiles)
+
+	switch {
+	case ignored:
+		loggerOrDiscard(logger).Info("file ignored", "file", fileName, "rule", rule)
+	case rule != "":
+		loggerOrDiscard(logger).Debug("file included", "file", fileName, "rule", rule)
+	}
+
+	return ignored
+}
+
+// FileIgnoreRule returns the rule of ignoreFiles that ignores the given file name, or an empty string if the file is not ignored.
+// Unlike IsFileIgnored, it does not log anything.
+func FileIgnoreRule(fileName string, ignoreFiles []string) string {
+	if rule, ignored := MatchIgnoreRules(fileName, ignoreFiles); ignored {
+		return rule
+	}
+
+	return ""
+}
+
+// IsColumnIgnored checks if a column of a table is ignored by the rules of ignore_columns, see ColumnIgnoreRule.
+// The decision is logged together with the rule that triggered it.
+//
+// Parameters:
+// - logger: The logger of the decision, nothing is logged if it is nil.
+// - fileName: The name of the file containing the table.
+// - tableName: The name of the table.
+// - columnName: The name of the column to check.
+// - ignoreColumns: The rules of ignore_columns per file or table.
+//
+// Return:
+// - A boolean value indicating whether the column is ignored. If true, the column is ignored.
+func IsColumnIgnored(logger *slog.Logger, fileName string, tableName string, columnName string, ignoreColumns map[string][]string) bool {
+	rule, ignored := ColumnIgnoreRule(fileName, tableName, columnName, ignoreColumns)
+
+	switch {
+	case ignored:
+		loggerOrDiscard(logger).Debug("column ignored", "table", tableName, "column", columnName, "rule", rule)
+	case rule != "":
+		loggerOrDiscard(logger).Debug("column included", "table", tableName, "column", columnName, "rule", rule)
+	}
+
+	return ignored
+}
+
+// ColumnIgnoreRule decides if a column of a table is ignored by the rules of ignore_columns.
+// The keys of ignore_columns are file names, table names or patterns (e.g. "*" for every table), see MatchIgnorePattern.
+// The rules of all matching keys are applied in order: first the rules of pattern keys, then the rules of exact keys,
+// so exact keys can include columns again that are ignored for every table.
+//
+// Parameters:
+// - fileName: The name of the file containing the table.
+// - tableName: The name of the table.
+// - columnName: The name of the column to check.
+// - ignoreColumns: The rules of ignore_columns per file or table.
+//
+// Return:
+// - string: The rule that decided about the column as "<key>: <rule>", or an empty string if no rule matched.
+// - bool: Whether the column is ignored.
+func ColumnIgnoreRule(fileName string, tableName string, columnName string, ignoreColumns map[string][]string) (string, bool) {
+	var patternKeys, exactKeys []string
+	for _, key := range SortedKeys(ignoreColumns) {
+		if !MatchIgnorePattern(key, fileName) && !MatchIgnorePattern(key, tableName) {
+			continue
+		}
+
+		if IsIgnorePattern(key) {
+			patternKeys = append(patternKeys, key)
+		} else {
+			exactKeys = append(exactKeys, key)
 		}
 	}
 
-	return false
-}
-
-// IsColumnIgnored checks if a given column name is present in a list of ignored columns for a specific file.//+
-// It returns true if the column is ignored, and false otherwise.//+
-// //+
-// Parameters://+
-// - fileName: The name of the file to check.//+
-// - columnName: The name of the column to check.//+
-// - ignoreColumns: A map where the keys are file names and the values are slices of strings containing the names of the columns to be ignored.//+
-// //+
-// Return://+
-// - A boolean value indicating whether the column is ignored. If true, the column is ignored.//+
-func IsColumnIgnored(fileName string, columnName string, ignoreColumns map[string][]string) bool {
-	for _, ignoreColumn := range ignoreColumns[fileName] {
-		if strings.EqualFold(ignoreColumn, columnName) {
-			fmt.Printf("  => column %v will be ignored\n", columnName)
-			return true
+	decidingRule, ignored := "", false
+	for _, key := range append(patternKeys, exactKeys...) {
+		if rule, matched := MatchIgnoreRules(columnName, ignoreColumns[key]); rule != "" {
+			decidingRule, ignored = key+": "+rule, matched
 		}
 	}
 
-	return false
+	return decidingRule, ignored
+}
+
+// MatchIgnoreRules decides if a name is ignored by a list of rules.
+// Every rule is a pattern (see MatchIgnorePattern) that ignores the matching names. Rules starting with "!" are negated
+// and include matching names again. If more than one rule matches, the last one wins, e.g. ["*_backup.sql", "!users_backup.sql"]
+// ignores every backup file except users_backup.sql.
+//
+// Parameters:
+// - name: The file or column name to check.
+// - rules: The rules in the order of the configuration.
+//
+// Return:
+// - string: The last rule that matched the name, or an empty string if no rule matched.
+// - bool: Whether the name is ignored.
+func MatchIgnoreRules(name string, rules []string) (string, bool) {
+	decidingRule, ignored := "", false
+
+	for _, rule := range rules {
+		pattern, negated := strings.CutPrefix(strings.TrimSpace(rule), "!")
+		if MatchIgnorePattern(pattern, name) {
+			decidingRule, ignored = rule, !negated
+		}
+	}
+
+	return decidingRule, ignored
+}
+
+// MatchIgnorePattern checks if a name matches a pattern case-insensitively.
+// The pattern can be the exact name, a glob pattern (e.g. "tmp_*", see MatchPattern) or a regular expression
+// enclosed in slashes (e.g. "/^tmp_\d+$/"). Invalid patterns do not match anything, see ValidateIgnorePattern.
+// Names of files in subdirectories of the input directory (e.g. "billing/invoices.sql") are also matched by their base name
+// with exact names and glob patterns, regular expressions are matched against the whole path.
+//
+// Parameters:
+// - pattern: The exact name, glob pattern or regular expression.
+// - name: The name to be checked.
+//
+// Return:
+// - A boolean value indicating whether the name matches the pattern.
+func MatchIgnorePattern(pattern string, name string) bool {
+	if expression, isRegexp := regexpPattern(pattern); isRegexp {
+		compiled, compileError := regexp.Compile("(?i)" + expression)
+		return compileError == nil && compiled.MatchString(name)
+	}
+
+	return MatchPattern(pattern, name) || (strings.Contains(name, "/") && MatchPattern(pattern, path.Base(name)))
+}
+
+// IsIgnorePattern checks if a pattern is a glob pattern or a regular expression instead of an exact name.
+func IsIgnorePattern(pattern string) bool {
+	_, isRegexp := regexpPattern(pattern)
+	return isRegexp || strings.ContainsAny(pattern, "*?[")
+}
+
+// ValidateIgnorePattern returns an error if a pattern of ignore_files or ignore_columns is not a valid glob pattern or regular expression.
+func ValidateIgnorePattern(pattern string) error {
+	pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "!")
+
+	if expression, isRegexp := regexpPattern(pattern); isRegexp {
+		if _, compileError := regexp.Compile(expression); compileError != nil {
+			return fmt.Errorf("invalid regular expression '%v': %v", pattern, strings.TrimPrefix(compileError.Error(), "error parsing regexp: "))
+		}
+		return nil
+	}
+
+	if _, matchError := path.Match(pattern, ""); matchError != nil {
+		return fmt.Errorf("invalid pattern '%v': %v", pattern, matchError)
+	}
+
+	return nil
+}
+
+// regexpPattern returns the regular expression of a pattern enclosed in slashes.
+func regexpPattern(pattern string) (string, bool) {
+	pattern = strings.TrimSpace(pattern)
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return pattern[1 : len(pattern)-1], true
+	}
+	return "", false
 }
@@ -0,0 +1,55 @@
+package src
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchIgnoreRules(t *testing.T) {
+	rules := []string{"warehouses.sql", "*_backup.sql", "!users_backup.sql", `/^tmp_\d+\.sql$/`}
+
+	for fileName, expected := range map[string]string{
+		"Warehouses.sql":    "warehouses.sql",
+		"orders_backup.sql": "*_backup.sql",
+		"users_backup.sql":  "",
+		"tmp_12.sql":        `/^tmp_\d+\.sql$/`,
+		"tmp_a.sql":         "",
+		"users.sql":         "",
+	} {
+		assert.Equal(t, expected, FileIgnoreRule(fileName, rules), fileName)
+	}
+
+	rule, ignored := MatchIgnoreRules("users_backup.sql", rules)
+	assert.Equal(t, "!users_backup.sql", rule)
+	assert.False(t, ignored)
+}
+
+func TestColumnIgnoreRule(t *testing.T) {
+	ignoreColumns := map[string][]string{
+		"*":         {"deleted_at"},
+		"users.sql": {"created_at", "tmp_*"},
+		"audit_log": {"!deleted_at"},
+	}
+
+	rule, ignored := ColumnIgnoreRule("orders.sql", "orders", "deleted_at", ignoreColumns)
+	assert.Equal(t, "*: deleted_at", rule)
+	assert.True(t, ignored)
+
+	rule, ignored = ColumnIgnoreRule("audit.sql", "audit_log", "deleted_at", ignoreColumns)
+	assert.Equal(t, "audit_log: !deleted_at", rule)
+	assert.False(t, ignored)
+
+	_, ignored = ColumnIgnoreRule("users.sql", "users", "tmp_token", ignoreColumns)
+	assert.True(t, ignored)
+
+	_, ignored = ColumnIgnoreRule("orders.sql", "orders", "tmp_token", ignoreColumns)
+	assert.False(t, ignored)
+}
+
+func TestValidateIgnorePattern(t *testing.T) {
+	assert.NoError(t, ValidateIgnorePattern("!*_backup.sql"))
+	assert.NoError(t, ValidateIgnorePattern(`/^tmp_\d+$/`))
+	assert.EqualError(t, ValidateIgnorePattern("/tmp_(/"), "invalid regular expression '/tmp_(/': missing closing ): `tmp_(`")
+	assert.EqualError(t, ValidateIgnorePattern("tmp_[a"), "invalid pattern 'tmp_[a': syntax error in pattern")
+}
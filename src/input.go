@@ -0,0 +1,186 @@
+package src
+
+import (
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultInputExtensions are the extensions of the files that are converted if include_extensions is not configured.
+var DefaultInputExtensions = []string{".sql"}
+
+// InputFile is a file of the input directory that is converted.
+type InputFile struct {
+	// Name is the path of the file relative to the input directory with forward slashes, e.g. "billing/invoices.sql"
+	Name string
+	// Path is the path of the file on disk, or within FS if it is set
+	Path string
+	// FS is the file system containing the file, e.g. an embed.FS. If it is nil, the file is read from disk.
+	FS fs.FS
+}
+
+// ReadFile reads the content of the file from disk or from its file system.
+func (file InputFile) ReadFile() ([]byte, error) {
+	if file.FS != nil {
+		return fs.ReadFile(file.FS, file.Path)
+	}
+	return os.ReadFile(file.Path)
+}
+
+// Dir returns the directory of the file relative to the input directory, or an empty string for files directly in the input directory.
+func (file InputFile) Dir() string {
+	if dir := path.Dir(file.Name); dir != "." {
+		return dir
+	}
+	return ""
+}
+
+// InputOptions controls which files of the input directory are converted.
+type InputOptions struct {
+	// Extensions are the extensions of the converted files, DefaultInputExtensions if empty
+	Extensions []string
+	// FollowSymlinks enables walking into symlinked directories. Symlinked files are always read.
+	FollowSymlinks bool
+	// Logger receives the skipped symlinks, nothing is logged if it is nil
+	Logger *slog.Logger
+}
+
+// InputOptions returns the options for walking the input directory of the configuration.
+func (conf *Config) InputOptions() InputOptions {
+	return InputOptions{Extensions: conf.IncludeExtensions, FollowSymlinks: conf.FollowSymlinks}
+}
+
+// MatchesExtension checks if a file name ends with one of the configured extensions. Extensions are compared case-insensitively
+// and may be given with or without the leading dot, e.g. "sql", ".sql" or ".up.sql".
+func (options InputOptions) MatchesExtension(fileName string) bool {
+	extensions := options.Extensions
+	if len(extensions) == 0 {
+		extensions = DefaultInputExtensions
+	}
+
+	for _, extension := range extensions {
+		extension = strings.ToLower(strings.TrimSpace(extension))
+		if extension != "" && strings.HasSuffix(strings.ToLower(fileName), "."+strings.TrimPrefix(extension, ".")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FindInputFiles walks the input directory recursively and returns every file with one of the configured extensions, sorted by name.
+// Hidden directories (e.g. ".git") are skipped. Symlinked files are read like regular files, symlinked directories are only walked
+// if FollowSymlinks is set. Every directory is walked at most once, so symlinks pointing to a parent directory do not cause a loop.
+// Broken symlinks are skipped.
+//
+// Parameters:
+// - dir: The input directory.
+// - options: The extensions of the converted files and the handling of symlinks.
+//
+// Return:
+// - []InputFile: The files to be converted.
+// - error: An error if the input directory or one of its subdirectories could not be read.
+func FindInputFiles(dir string, options InputOptions) ([]InputFile, error) {
+	var files []InputFile
+	visited := make(map[string]bool)
+	logger := loggerOrDiscard(options.Logger)
+
+	var walk func(diskDir string, relativeDir string) error
+	walk = func(diskDir string, relativeDir string) error {
+		if realDir, realError := filepath.EvalSymlinks(diskDir); realError == nil {
+			if visited[realDir] {
+				return nil
+			}
+			visited[realDir] = true
+		}
+
+		entries, readError := os.ReadDir(diskDir)
+		if readError != nil {
+			return readError
+		}
+
+		for _, entry := range entries {
+			name := path.Join(relativeDir, entry.Name())
+			diskPath := filepath.Join(diskDir, entry.Name())
+			isDir := entry.IsDir()
+
+			if entry.Type()&fs.ModeSymlink != 0 {
+				info, statError := os.Stat(diskPath)
+				if statError != nil {
+					logger.Warn("skipping broken symlink", "path", name)
+					continue
+				}
+
+				if info.IsDir() && !options.FollowSymlinks {
+					logger.Info("skipping symlinked directory, set follow_symlinks to convert it", "path", name)
+					continue
+				}
+				isDir = info.IsDir()
+			}
+
+			if isDir {
+				if strings.HasPrefix(entry.Name(), ".") {
+					continue
+				}
+				if walkError := walk(diskPath, name); walkError != nil {
+					return walkError
+				}
+				continue
+			}
+
+			if options.MatchesExtension(entry.Name()) {
+				files = append(files, InputFile{Name: name, Path: diskPath})
+			}
+		}
+
+		return nil
+	}
+
+	if walkError := walk(dir, ""); walkError != nil {
+		return nil, walkError
+	}
+
+	return files, nil
+}
+
+// FindInputFilesFS walks a file system like FindInputFiles and returns every file with one of the configured extensions, sorted by name.
+// This allows converting SQL files of an embed.FS or fstest.MapFS. Use fs.Sub to convert a subdirectory of the file system.
+// Hidden directories are skipped, symlinks are not followed.
+//
+// Parameters:
+// - fsys: The file system containing the SQL files.
+// - options: The extensions of the converted files.
+//
+// Return:
+// - []InputFile: The files to be converted, read from fsys.
+// - error: An error if the file system could not be read.
+func FindInputFilesFS(fsys fs.FS, options InputOptions) ([]InputFile, error) {
+	var files []InputFile
+
+	walkError := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if name != "." && strings.HasPrefix(entry.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if entry.Type().IsRegular() && options.MatchesExtension(entry.Name()) {
+			files = append(files, InputFile{Name: name, Path: name, FS: fsys})
+		}
+		return nil
+	})
+
+	if walkError != nil {
+		return nil, walkError
+	}
+
+	return files, nil
+}
@@ -0,0 +1,45 @@
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindInputFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"users.sql", "README.md", ".gitkeep", "billing/invoices.SQL", "billing/archive/old.up.sql", ".git/hooks.sql"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("CREATE TABLE t (id INT)"), 0644))
+	}
+
+	shared := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(shared, "products.sql"), []byte("CREATE TABLE products (id INT)"), 0644))
+	assert.NoError(t, os.Symlink(shared, filepath.Join(dir, "shared")))
+	assert.NoError(t, os.Symlink(dir, filepath.Join(dir, "billing", "loop")))
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "missing.sql"), filepath.Join(dir, "broken.sql")))
+
+	names := func(files []InputFile) []string {
+		var names []string
+		for _, file := range files {
+			names = append(names, file.Name)
+		}
+		return names
+	}
+
+	files, err := FindInputFiles(dir, InputOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"billing/archive/old.up.sql", "billing/invoices.SQL", "users.sql"}, names(files))
+	assert.Equal(t, "billing/archive", files[0].Dir())
+	assert.Equal(t, "", files[2].Dir())
+
+	files, err = FindInputFiles(dir, InputOptions{Extensions: []string{"up.sql"}, FollowSymlinks: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"billing/archive/old.up.sql"}, names(files))
+
+	files, err = FindInputFiles(dir, InputOptions{FollowSymlinks: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"billing/archive/old.up.sql", "billing/invoices.SQL", "shared/products.sql", "users.sql"}, names(files))
+}
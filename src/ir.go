@@ -0,0 +1,209 @@
+package src
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// SchemaVersion is the version of the JSON format of Schema. It is increased whenever the format changes incompatibly.
+const SchemaVersion = 1
+
+// Schema is the intermediate representation of the parsed tables written by "s2i dump".
+// It can be loaded again with LoadSchema and converted instead of the input directory (see Config.InputSchema),
+// so other tools can produce or consume the tables without parsing SQL.
+type Schema struct {
+	Version int `json:"version"`
+	// Tables are the tables as written in the SQL files, see ParseTable
+	Tables []SQL `json:"tables"`
+	// Combined are the combined structures of combine_tables and the tables they match. They are only informative and ignored by LoadSchema.
+	Combined []CombinedSchema `json:"combined,omitempty"`
+}
+
+// CombinedSchema is a combined structure of combine_tables resolved against the parsed tables.
+type CombinedSchema struct {
+	// Key is the key of the combined structure in combine_tables
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	// Tables are the table references of the configuration, MatchedTables the names of the tables they match
+	Tables              []string          `json:"tables"`
+	MatchedTables       []string          `json:"matched_tables"`
+	ConvertSingleTables bool              `json:"convert_single_tables"`
+	OnCollision         string            `json:"on_collision,omitempty"`
+	Collisions          []ColumnCollision `json:"collisions,omitempty"`
+	// Error is the reason the combined structure cannot be created, e.g. a table that was not found
+	Error string `json:"error,omitempty"`
+}
+
+// BuildSchema creates the intermediate representation of parsed tables together with the combined structures of the configuration.
+// Columns ignored by ignore_columns are part of the tables but not of the collisions of combined structures.
+//
+// Parameters:
+// - tables: The tables to be written, see ParseInput.
+//
+// Return:
+// - Schema: The intermediate representation.
+func (s2i *SQL2Interface) BuildSchema(tables []SQL) Schema {
+	schema := Schema{Version: SchemaVersion, Tables: tables}
+	if schema.Tables == nil {
+		schema.Tables = []SQL{}
+	}
+
+	for _, key := range SortedKeys(s2i.Config.CombineTables) {
+		combine := s2i.Config.CombineTables[key]
+		combined := CombinedSchema{
+			Key:                 key,
+			Name:                combine.Name,
+			Tables:              combine.Tables,
+			MatchedTables:       []string{},
+			ConvertSingleTables: combine.ConvertSingleTables,
+			OnCollision:         combine.OnCollision,
+		}
+
+		var matched []SQL
+		var notFound []string
+		for _, reference := range combine.Tables {
+			found := false
+			for _, table := range tables {
+				if MatchesTable(reference, table) {
+					found = true
+					matched = append(matched, s2i.withoutIgnoredColumns(table))
+					combined.MatchedTables = append(combined.MatchedTables, table.OriginalName)
+				}
+			}
+			if !found {
+				notFound = append(notFound, reference)
+			}
+		}
+
+		combined.Collisions = FindColumnCollisions(matched...)
+		switch {
+		case len(notFound) > 0:
+			combined.Error = fmt.Sprintf("tables were not found: %v", strings.Join(notFound, ", "))
+		case len(combined.Collisions) > 0 && FirstNonEmpty(combine.OnCollision, CollisionError) == CollisionError:
+			combined.Error = "column collisions: " + FormatColumnCollisions(combined.Collisions)
+		}
+
+		schema.Combined = append(schema.Combined, combined)
+	}
+
+	return schema
+}
+
+// withoutIgnoredColumns returns a copy of a table without the columns ignored by ignore_columns.
+func (s2i *SQL2Interface) withoutIgnoredColumns(table SQL) SQL {
+	filtered := table
+	filtered.Columns = nil
+	for _, column := range table.Columns {
+		if _, ignored := ColumnIgnoreRule(table.FileName, table.OriginalName, column.OriginalName, s2i.Config.IgnoreColumns); !ignored {
+			filtered.Columns = append(filtered.Columns, column)
+		}
+	}
+	return filtered
+}
+
+// SchemaInput parses the tables of the configured input (see ParseInput) and creates their intermediate representation.
+//
+// Return:
+// - Schema: The intermediate representation. Files that could not be parsed are skipped.
+// - error: The errors of ParseInput, see ParseInput.
+func (s2i *SQL2Interface) SchemaInput() (Schema, error) {
+	tables, err := s2i.ParseInput()
+
+	if _, isConfigError := err.(ConfigError); isConfigError {
+		return Schema{}, err
+	}
+
+	return s2i.BuildSchema(tables), err
+}
+
+// WriteSchema writes the intermediate representation as indented JSON.
+//
+// Parameters:
+// - w: The writer the JSON is written to, e.g. os.Stdout.
+// - schema: The intermediate representation, see BuildSchema.
+//
+// Return:
+// - error: An error if the JSON could not be written.
+func WriteSchema(w io.Writer, schema Schema) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(schema)
+}
+
+// LoadSchema reads the intermediate representation written by WriteSchema.
+// Only the tables are used, the names and types are the names and SQL types as written in the SQL files.
+// If the original name of a table or column is missing, its name is used instead, so hand written schemas only need names and types.
+//
+// Parameters:
+// - r: The reader the JSON is read from.
+//
+// Return:
+// - Schema: The intermediate representation, the table and column names are set to their original names.
+// - error: An error if the JSON is invalid or its version is not supported,
+// or ConversionErrors containing a ParseError for every table or column without name or type.
+func LoadSchema(r io.Reader) (Schema, error) {
+	var schema Schema
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if decodeError := decoder.Decode(&schema); decodeError != nil {
+		return Schema{}, fmt.Errorf("invalid schema: %w", decodeError)
+	}
+
+	if schema.Version < 1 || schema.Version > SchemaVersion {
+		return Schema{}, fmt.Errorf("unsupported schema version %v, expected %v", schema.Version, SchemaVersion)
+	}
+
+	var conversionErrors ConversionErrors
+	for i := range schema.Tables {
+		table := &schema.Tables[i]
+		table.OriginalName = FirstNonEmpty(table.OriginalName, table.TableName)
+		table.TableName = table.OriginalName
+
+		if table.OriginalName == "" {
+			conversionErrors.Add(ParseError{File: table.FileName, Err: fmt.Errorf("table %v has no name", i)})
+			continue
+		}
+
+		for j := range table.Columns {
+			column := &table.Columns[j]
+			column.OriginalName = FirstNonEmpty(column.OriginalName, column.Name)
+			column.Name = column.OriginalName
+
+			if column.OriginalName == "" || column.Type == "" {
+				conversionErrors.Add(ParseError{File: table.FileName, Table: table.OriginalName, Err: fmt.Errorf("column %v has no name or type", j)})
+			}
+		}
+	}
+
+	if conversionErrors.Err() != nil {
+		return Schema{}, conversionErrors
+	}
+
+	return schema, nil
+}
+
+// LoadSchemaFile reads the intermediate representation from a file, see LoadSchema.
+//
+// Parameters:
+// - filePath: The path of the JSON file.
+//
+// Return:
+// - Schema: The intermediate representation.
+// - error: An error containing the path if the file could not be read or is invalid.
+func LoadSchemaFile(filePath string) (Schema, error) {
+	file, openError := os.Open(filePath)
+	if openError != nil {
+		return Schema{}, openError
+	}
+	defer file.Close()
+
+	schema, loadError := LoadSchema(file)
+	if loadError != nil {
+		return Schema{}, fmt.Errorf("%v: %w", filePath, loadError)
+	}
+	return schema, nil
+}
@@ -0,0 +1,83 @@
+package src
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaRoundTrip(t *testing.T) {
+	tables, _ := ParseDDL(strings.NewReader(testDDL), DDLOptions{FileName: "schema.sql"})
+	s2i := NewSQL2InterfaceFromConfig(&Config{
+		IgnoreColumns: map[string][]string{"*": {"created_at"}},
+		CombineTables: map[string]TableCombine{
+			"all":     {Name: "All", Tables: []string{"users", "orders"}},
+			"missing": {Name: "Missing", Tables: []string{"payments"}},
+		},
+	})
+
+	schema := s2i.BuildSchema(tables)
+	assert.Equal(t, []CombinedSchema{
+		{
+			Key: "all", Name: "All", Tables: []string{"users", "orders"}, MatchedTables: []string{"users", "orders"},
+			Collisions: []ColumnCollision{{Column: "id", Tables: []string{"users", "orders"}}},
+			Error:      "column collisions: id (users, orders)",
+		},
+		{Key: "missing", Name: "Missing", Tables: []string{"payments"}, MatchedTables: []string{}, Error: "tables were not found: payments"},
+	}, schema.Combined)
+
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteSchema(&buffer, schema))
+	assert.Contains(t, buffer.String(), `"constraints": "not null"`)
+
+	loaded, err := LoadSchema(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, tables, loaded.Tables)
+}
+
+func TestLoadSchema(t *testing.T) {
+	// hand written schemas only need names and types
+	schema, err := LoadSchema(strings.NewReader(`{"version": 1, "tables": [{"table_name": "users", "columns": [{"name": "id", "type": "int"}]}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []SQL{{TableName: "users", OriginalName: "users", Columns: []Column{{Name: "id", OriginalName: "id", Type: "int"}}}}, schema.Tables)
+
+	_, err = LoadSchema(strings.NewReader(`{"version": 2, "tables": []}`))
+	assert.EqualError(t, err, "unsupported schema version 2, expected 1")
+
+	_, err = LoadSchema(strings.NewReader(`{"version": 1, "tables": [], "views": []}`))
+	assert.ErrorContains(t, err, `unknown field "views"`)
+
+	_, err = LoadSchema(strings.NewReader(`{"version": 1, "tables": [{"file_name": "users.sql", "table_name": "users", "columns": [{"name": "id"}]}]}`))
+	assert.EqualError(t, err, "users.sql: table users: column 0 has no name or type")
+}
+
+func TestRunInputSchema(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.json")
+	assert.NoError(t, os.WriteFile(schemaPath, []byte(`{"version": 1, "tables": [
+		{"file_name": "users.sql", "table_name": "users", "columns": [{"name": "id", "type": "int", "comment": "primary key"}]},
+		{"file_name": "audit.sql", "table_name": "audit", "columns": [{"name": "id", "type": "int"}]}
+	]}`), 0644))
+
+	conf := &Config{
+		InputSchema: schemaPath,
+		IgnoreFiles: []string{"audit.sql"},
+		Output:      OutputConfig{Go: &GoOutput{OutputDir: dir, OutputFile: "models.go", PackageName: "models"}},
+	}
+	// the input directory is not required if the tables are loaded from the schema
+	assert.NoError(t, conf.Validate())
+	assert.NoError(t, NewSQL2InterfaceFromConfig(conf).Run())
+
+	content, readError := os.ReadFile(filepath.Join(dir, "models.go"))
+	assert.NoError(t, readError)
+	assert.Equal(t, GeneratedHeader+"\n\npackage models\n\n\ntype Users struct {\n\t// primary key\r\n\tId int\r\n}", string(content))
+
+	conf.InputSchema = filepath.Join(dir, "missing.json")
+	var configError ConfigError
+	assert.ErrorAs(t, NewSQL2InterfaceFromConfig(conf).Run(), &configError)
+	assert.Contains(t, configError.Message, "missing.json")
+}
@@ -0,0 +1,200 @@
+package src
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// KeepBegin starts a region of an output file that is kept on regeneration, followed by the name of the region,
+	// e.g. "// s2i:keep-begin Users"
+	KeepBegin = "// s2i:keep-begin"
+	// KeepEnd ends a region started by KeepBegin
+	KeepEnd = "// s2i:keep-end"
+	// KeepImports is the name of the region at the top of every output file if keep_regions is set, e.g. for imports used by custom methods
+	KeepImports = "imports"
+)
+
+// KeepRegion is a region of an output file between the markers KeepBegin and KeepEnd.
+type KeepRegion struct {
+	Name string
+	// Lines are the lines between the markers as written in the file
+	Lines []string
+}
+
+// ParseKeepRegions finds the keep regions of a file.
+//
+// Parameters:
+// - content: The content of the file.
+//
+// Return:
+// - []KeepRegion: The regions in the order of the file.
+// - error: An error if a region has no name, is not closed, is nested in another region or a name is used twice.
+func ParseKeepRegions(content string) ([]KeepRegion, error) {
+	var regions []KeepRegion
+	var current *KeepRegion
+	names := make(map[string]bool)
+	beginLine := 0
+
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, KeepBegin):
+			name := strings.TrimSpace(strings.TrimPrefix(trimmed, KeepBegin))
+			if name == "" {
+				return nil, fmt.Errorf("keep region in line %v has no name", i+1)
+			}
+			if current != nil {
+				return nil, fmt.Errorf("keep region %v in line %v is not closed before line %v", current.Name, beginLine, i+1)
+			}
+			if names[name] {
+				return nil, fmt.Errorf("keep region %v in line %v is defined twice", name, i+1)
+			}
+			names[name] = true
+			current = &KeepRegion{Name: name}
+			beginLine = i + 1
+		case trimmed == KeepEnd:
+			if current == nil {
+				return nil, fmt.Errorf("end of keep region in line %v has no beginning", i+1)
+			}
+			regions = append(regions, *current)
+			current = nil
+		case current != nil:
+			current.Lines = append(current.Lines, line)
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("keep region %v in line %v is not closed", current.Name, beginLine)
+	}
+
+	return regions, nil
+}
+
+// MergeKeepRegions inserts the content of the keep regions of an existing file into the regions of the same name of its generated content.
+// Regions of the existing file that are not part of the generated content, e.g. the region of a table that was removed,
+// are appended to the end, so custom code is never lost. Empty regions are dropped.
+//
+// Parameters:
+// - generated: The generated content of the file.
+// - existing: The content of the file on disk.
+//
+// Return:
+// - string: The generated content containing the regions of the existing file.
+// - []string: The names of the regions that were appended to the end.
+// - error: An error if the keep regions of the existing file are invalid, see ParseKeepRegions.
+func MergeKeepRegions(generated string, existing string) (string, []string, error) {
+	regions, parseError := ParseKeepRegions(existing)
+	if parseError != nil {
+		return "", nil, parseError
+	}
+
+	kept := make(map[string][]string)
+	for _, region := range regions {
+		kept[region.Name] = region.Lines
+	}
+
+	var merged []string
+	inserted := make(map[string]bool)
+	replacing := false
+
+	for _, line := range strings.Split(generated, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, KeepBegin):
+			merged = append(merged, line)
+			name := strings.TrimSpace(strings.TrimPrefix(trimmed, KeepBegin))
+			if lines, exists := kept[name]; exists {
+				merged = append(merged, lines...)
+				inserted[name] = true
+				replacing = true
+			}
+			continue
+		case trimmed == KeepEnd:
+			replacing = false
+		case replacing:
+			// the generated content of a region is replaced by the content of the existing file
+			continue
+		}
+		merged = append(merged, line)
+	}
+
+	content := strings.Join(merged, "\n")
+	var appended []string
+
+	for _, region := range regions {
+		if inserted[region.Name] || strings.TrimSpace(strings.Join(region.Lines, "")) == "" {
+			continue
+		}
+
+		lines := append(append([]string{KeepBegin + " " + region.Name}, region.Lines...), KeepEnd)
+		content += "\n\n" + strings.Join(lines, "\n")
+		appended = append(appended, region.Name)
+	}
+
+	return content, appended, nil
+}
+
+// keepRegion returns an empty keep region if keep_regions is set, otherwise an empty string.
+func (s2i *SQL2Interface) keepRegion(name string) string {
+	if !s2i.Config.KeepRegions {
+		return ""
+	}
+	return KeepBegin + " " + name + "\n" + KeepEnd
+}
+
+// importsKeepRegion returns the keep region written below the header of every output file, see KeepImports.
+func (s2i *SQL2Interface) importsKeepRegion() string {
+	if region := s2i.keepRegion(KeepImports); region != "" {
+		return "\n" + region + "\n"
+	}
+	return ""
+}
+
+// structureKeepRegion returns the keep region written below a structure, named after the structure.
+func (s2i *SQL2Interface) structureKeepRegion(name string) string {
+	if region := s2i.keepRegion(name); region != "" {
+		return "\n\n" + region
+	}
+	return ""
+}
+
+// preserveKeepRegions inserts the keep regions of the existing output files into the generated files, see MergeKeepRegions.
+// Files whose existing keep regions are invalid are skipped, so they are not overwritten.
+//
+// Parameters:
+// - generated: The generated files.
+//
+// Return:
+// - []GeneratedFile: The generated files containing the keep regions of the existing files.
+// - error: ConversionErrors containing a WriteError for every file with invalid keep regions, or nil.
+func (s2i *SQL2Interface) preserveKeepRegions(generated []GeneratedFile) ([]GeneratedFile, error) {
+	var preserved []GeneratedFile
+	var conversionErrors ConversionErrors
+
+	for _, file := range generated {
+		existing, readError := os.ReadFile(file.Path())
+		if readError != nil {
+			preserved = append(preserved, file)
+			continue
+		}
+
+		content, appended, mergeError := MergeKeepRegions(file.Content, string(existing))
+		if mergeError != nil {
+			conversionErrors.Add(WriteError{Path: file.Path(), Err: mergeError})
+			continue
+		}
+
+		for _, name := range appended {
+			s2i.logger().Warn("keep region not found in the generated file, appending it to the end", "path", file.Path(), "region", name)
+		}
+
+		file.Content = content
+		preserved = append(preserved, file)
+	}
+
+	return preserved, conversionErrors.Err()
+}
@@ -0,0 +1,71 @@
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeepRegions(t *testing.T) {
+	regions, err := ParseKeepRegions("type Users struct{}\n// s2i:keep-begin Users\nfunc (Users) Name() {}\r\n  // s2i:keep-end\n// s2i:keep-begin empty\n// s2i:keep-end")
+	assert.NoError(t, err)
+	assert.Equal(t, []KeepRegion{{Name: "Users", Lines: []string{"func (Users) Name() {}\r"}}, {Name: "empty"}}, regions)
+
+	for content, message := range map[string]string{
+		"// s2i:keep-begin\n// s2i:keep-end":       "keep region in line 1 has no name",
+		"// s2i:keep-begin a\n// s2i:keep-begin b": "keep region a in line 1 is not closed before line 2",
+		"// s2i:keep-begin a":                      "keep region a in line 1 is not closed",
+		"// s2i:keep-end":                          "end of keep region in line 1 has no beginning",
+		"// s2i:keep-begin a\n// s2i:keep-end\n// s2i:keep-begin a\n// s2i:keep-end": "keep region a in line 3 is defined twice",
+	} {
+		_, err := ParseKeepRegions(content)
+		assert.EqualError(t, err, message)
+	}
+}
+
+func TestMergeKeepRegions(t *testing.T) {
+	generated := "package models\n\n// s2i:keep-begin imports\n// s2i:keep-end\n\ntype Users struct{}\n\n// s2i:keep-begin Users\n// s2i:keep-end"
+	existing := "// s2i:keep-begin imports\nimport \"strings\"\n// s2i:keep-end\n// s2i:keep-begin Users\n// s2i:keep-end\n// s2i:keep-begin Orders\nfunc (Orders) Total() {}\n// s2i:keep-end"
+
+	merged, appended, err := MergeKeepRegions(generated, existing)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Orders"}, appended)
+	assert.Equal(t, "package models\n\n// s2i:keep-begin imports\nimport \"strings\"\n// s2i:keep-end\n\ntype Users struct{}\n\n// s2i:keep-begin Users\n// s2i:keep-end"+
+		"\n\n// s2i:keep-begin Orders\nfunc (Orders) Total() {}\n// s2i:keep-end", merged)
+
+	// merging again keeps the content unchanged
+	again, _, _ := MergeKeepRegions(generated, merged)
+	assert.Equal(t, merged, again)
+}
+
+func TestRunKeepRegions(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "sql")
+	assert.NoError(t, os.MkdirAll(input, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(input, "users.sql"), []byte("CREATE TABLE users (id INT)"), 0644))
+
+	s2i := NewSQL2InterfaceFromConfig(&Config{
+		Input:       input,
+		KeepRegions: true,
+		Output:      OutputConfig{Go: &GoOutput{OutputDir: dir, OutputFile: "models.go", PackageName: "models"}},
+	})
+	assert.NoError(t, s2i.Run())
+
+	path := filepath.Join(dir, "models.go")
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, GeneratedHeader+"\n\npackage models\n\n// s2i:keep-begin imports\n// s2i:keep-end\n\n\ntype Users struct {\n\tId int\r\n}\n\n// s2i:keep-begin Users\n// s2i:keep-end", string(content))
+
+	custom := []byte(string(content[:len(content)-len(KeepEnd)]) + "func (Users) Table() string { return \"users\" }\n" + KeepEnd)
+	assert.NoError(t, os.WriteFile(path, custom, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(input, "users.sql"), []byte("CREATE TABLE users (id INT, name TEXT)"), 0644))
+	assert.NoError(t, s2i.Run())
+
+	content, _ = os.ReadFile(path)
+	assert.Contains(t, string(content), "\tName string\r\n}\n\n// s2i:keep-begin Users\nfunc (Users) Table() string { return \"users\" }\n// s2i:keep-end")
+
+	diffs, err := s2i.Check()
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+}
@@ -0,0 +1,98 @@
+package src
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// LevelTrace is the level of the details of every parsed column, only printed with the log level "debug".
+const LevelTrace = slog.LevelDebug - 4
+
+// LogLevels maps the names of the log levels to the lowest level of the messages that are printed.
+//   - quiet: only errors
+//   - normal: converted and written files, ignored files and renamed identifiers
+//   - verbose: additionally ignored columns, arbitrary fields and the progress of combined structures
+//   - debug: additionally every parsed column and cached parse
+var LogLevels = map[string]slog.Level{
+	"quiet":   slog.LevelError,
+	"normal":  slog.LevelInfo,
+	"verbose": slog.LevelDebug,
+	"debug":   LevelTrace,
+}
+
+// LogFormats are the supported formats of log messages: "text" for terminals and "json" for CI systems.
+var LogFormats = []string{"text", "json"}
+
+// NewLogger creates a logger for the messages of SQL2Interface.
+//
+// Parameters:
+// - output: The writer the log messages are written to, e.g. os.Stderr.
+// - level: The name of the log level, see LogLevels.
+// - format: The format of the log messages, see LogFormats.
+//
+// Return:
+// - *slog.Logger: The logger.
+// - error: An error if the level or the format is unknown.
+func NewLogger(output io.Writer, level string, format string) (*slog.Logger, error) {
+	minLevel, knownLevel := LogLevels[level]
+	if !knownLevel {
+		return nil, fmt.Errorf("unknown log level '%v', expected one of %v", level, strings.Join(SortedKeys(LogLevels), ", "))
+	}
+
+	options := &slog.HandlerOptions{Level: minLevel, ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+		if len(groups) > 0 {
+			return attr
+		}
+
+		// slog prints LevelTrace as "DEBUG-4"
+		if attr.Key == slog.LevelKey && attr.Value.Any() == LevelTrace {
+			return slog.String(slog.LevelKey, "TRACE")
+		}
+
+		// timestamps are only useful for machine readable logs
+		if attr.Key == slog.TimeKey && format == "text" {
+			return slog.Attr{}
+		}
+		return attr
+	}}
+
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(output, options)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(output, options)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format '%v', expected one of %v", format, strings.Join(LogFormats, ", "))
+	}
+}
+
+// discardHandler is a slog.Handler that drops every message.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (handler discardHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return handler
+}
+func (handler discardHandler) WithGroup(string) slog.Handler {
+	return handler
+}
+
+// discardLogger is used if no logger is set, so the package is silent when used as a library.
+var discardLogger = slog.New(discardHandler{})
+
+// loggerOrDiscard returns the logger or a logger dropping every message if it is nil.
+func loggerOrDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return discardLogger
+	}
+	return logger
+}
+
+// logger returns the logger of the SQL2Interface instance, see SQL2Interface.Logger.
+func (s2i *SQL2Interface) logger() *slog.Logger {
+	return loggerOrDiscard(s2i.Logger)
+}
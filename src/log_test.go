@@ -0,0 +1,56 @@
+package src
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogger(t *testing.T) {
+	var output bytes.Buffer
+
+	logger, err := NewLogger(&output, "verbose", "text")
+	assert.NoError(t, err)
+	logger.Debug("column ignored", "column", "email")
+	logger.Log(context.Background(), LevelTrace, "parsed column", "column", "id")
+	assert.Equal(t, "level=DEBUG msg=\"column ignored\" column=email\n", output.String())
+
+	output.Reset()
+	logger, err = NewLogger(&output, "debug", "json")
+	assert.NoError(t, err)
+	logger.Log(context.Background(), LevelTrace, "parsed column")
+	assert.Contains(t, output.String(), `"level":"TRACE","msg":"parsed column"`)
+
+	_, err = NewLogger(&output, "loud", "text")
+	assert.EqualError(t, err, "unknown log level 'loud', expected one of debug, normal, quiet, verbose")
+	_, err = NewLogger(&output, "normal", "xml")
+	assert.EqualError(t, err, "unknown log format 'xml', expected one of text, json")
+}
+
+func TestGenerateLogger(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "users.sql"), []byte("CREATE TABLE users (id INT, email TEXT)"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "users_backup.sql"), []byte("CREATE TABLE users_backup (id INT)"), 0644))
+
+	var output bytes.Buffer
+	s2i := NewSQL2InterfaceFromConfig(&Config{
+		Input:         dir,
+		IgnoreFiles:   []string{"*_backup.sql"},
+		IgnoreColumns: map[string][]string{"users": {"email"}},
+		Output:        OutputConfig{Go: &GoOutput{OutputDir: dir, OutputFile: "types.go"}},
+	})
+	s2i.Logger, _ = NewLogger(&output, "normal", "text")
+
+	_, err := s2i.GenerateInput()
+	assert.NoError(t, err)
+	assert.Equal(t, "level=INFO msg=\"converting file\" file=users.sql\nlevel=INFO msg=\"file ignored\" file=users_backup.sql rule=*_backup.sql\n", output.String())
+
+	// without a logger nothing is logged
+	s2i.Logger = nil
+	_, err = s2i.GenerateInput()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,227 @@
+package src
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// naming strategies for types, fields and json tags
+const (
+	NamingLegacy = ""
+	NamingPascal = "pascal"
+	NamingCamel  = "camel"
+	NamingSnake  = "snake"
+	// NamingOriginal keeps the name as written in the SQL file. Only used for json tags.
+	NamingOriginal = "original"
+)
+
+// GoInitialisms contains the common initialisms that are written in upper case in Go identifiers (e.g. UserID, APIKey).
+var GoInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID", "IP", "JSON", "LHS",
+	"QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID", "UUID", "URI",
+	"URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// irregularPlurals maps irregular english plurals to their singular form.
+var irregularPlurals = map[string]string{
+	"people":   "person",
+	"children": "child",
+	"men":      "man",
+	"women":    "woman",
+	"data":     "datum",
+	"indices":  "index",
+	"statuses": "status",
+	"media":    "medium",
+}
+
+// Naming returns the naming configuration for the given output type.
+// If no naming is configured, the zero value is returned which keeps the legacy naming.
+func (s2i *SQL2Interface) Naming(definitionType string) NamingConfig {
+	if s2i.Config == nil {
+		return NamingConfig{}
+	}
+	return s2i.Config.Naming[definitionType]
+}
+
+// TypeName converts an SQL table name into the name of an interface or struct.
+// An explicit entry in the rename map takes precedence, otherwise the name is optionally singularized,
+// converted with the configured strategy and surrounded by the configured prefix and suffix.
+//
+// Parameters:
+// - table: The table name as written in the SQL file, e.g. "order_items".
+//
+// Return:
+// - The name of the structure, e.g. "OrderItem".
+func (naming NamingConfig) TypeName(table string) string {
+	if renamed, exists := naming.lookupRename(table); exists {
+		return renamed
+	}
+
+	words := SplitWords(table)
+	if naming.Singularize && len(words) > 0 {
+		words[len(words)-1] = Singularize(words[len(words)-1])
+	}
+
+	return naming.TypePrefix + naming.applyStrategy(naming.Types, words, table) + naming.TypeSuffix
+}
+
+// FieldName converts an SQL column name into the name of an interface or struct field.
+// The rename map is checked for "<table>.<column>" first and for "<column>" second.
+//
+// Parameters:
+// - table: The table name as written in the SQL file.
+// - column: The column name as written in the SQL file, e.g. "created_at".
+//
+// Return:
+// - The name of the field, e.g. "CreatedAt".
+func (naming NamingConfig) FieldName(table string, column string) string {
+	if renamed, exists := naming.lookupRename(table + "." + column); exists {
+		return renamed
+	}
+	if renamed, exists := naming.lookupRename(column); exists {
+		return renamed
+	}
+
+	return naming.FieldPrefix + naming.applyStrategy(naming.Fields, SplitWords(column), column) + naming.FieldSuffix
+}
+
+// PrefixedFieldName creates the name of a field that is prefixed with its table name, e.g. "ProductPriceId".
+// It is used to resolve column collisions in combined structures.
+func (naming NamingConfig) PrefixedFieldName(table string, column string) string {
+	if naming.Fields == NamingLegacy {
+		return ToPascalCase(table) + ToPascalCase(column)
+	}
+
+	words := append(SplitWords(table), SplitWords(column)...)
+	return naming.FieldPrefix + naming.applyStrategy(naming.Fields, words, column) + naming.FieldSuffix
+}
+
+// JSONName converts an SQL column name into the name used in json tags.
+// It returns an empty string if no json tag strategy is configured.
+func (naming NamingConfig) JSONName(column string) string {
+	switch naming.JSONTags {
+	case NamingLegacy:
+		return ""
+	case NamingOriginal:
+		return column
+	default:
+		return naming.applyStrategy(naming.JSONTags, SplitWords(column), column)
+	}
+}
+
+// lookupRename looks up a name in the rename map. An exact match is preferred, otherwise the name is looked up case-insensitively.
+// The keys are checked in alphabetical order, so the result does not depend on the order of the map.
+func (naming NamingConfig) lookupRename(name string) (string, bool) {
+	if renamed, exists := naming.Rename[name]; exists {
+		return renamed, true
+	}
+	for _, original := range SortedKeys(naming.Rename) {
+		if strings.EqualFold(original, name) {
+			return naming.Rename[original], true
+		}
+	}
+	return "", false
+}
+
+// applyStrategy joins the words of a name according to a naming strategy.
+// The legacy strategy title-cases the original name, which keeps underscores (e.g. "Created_at").
+func (naming NamingConfig) applyStrategy(strategy string, words []string, original string) string {
+	switch strategy {
+	case NamingPascal, NamingCamel:
+		result := ""
+		for i, word := range words {
+			if i == 0 && strategy == NamingCamel {
+				result += word
+				continue
+			}
+			result += naming.capitalize(word)
+		}
+		return result
+	case NamingSnake:
+		return strings.Join(words, "_")
+	default:
+		caser := cases.Title(language.Und, cases.NoLower)
+		return caser.String(strings.ToLower(original))
+	}
+}
+
+// capitalize upper-cases the first letter of a word or the whole word if it is a known initialism.
+func (naming NamingConfig) capitalize(word string) string {
+	if naming.Initialisms {
+		for _, initialism := range append(GoInitialisms, naming.ExtraInitialisms...) {
+			if strings.EqualFold(initialism, word) {
+				return strings.ToUpper(word)
+			}
+		}
+	}
+
+	runes := []rune(word)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// SplitWords splits a name into lower-case words at underscores, dashes, spaces and camelCase boundaries.
+//
+// Parameters:
+// - name: The name to be split, e.g. "order_items" or "userId".
+//
+// Return:
+// - The lower-case words of the name, e.g. ["order", "items"] or ["user", "id"].
+func SplitWords(name string) []string {
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+
+	return words
+}
+
+// Singularize converts a lower-case english plural into its singular form using a small set of rules.
+// Words that do not look like plurals are returned unchanged.
+//
+// Parameters:
+// - word: The word to be singularized, e.g. "categories".
+//
+// Return:
+// - The singular form of the word, e.g. "category".
+func Singularize(word string) string {
+	if singular, exists := irregularPlurals[word]; exists {
+		return singular
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zzes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s") && len(word) > 1:
+		return strings.TrimSuffix(word, "s")
+	default:
+		return word
+	}
+}
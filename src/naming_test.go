@@ -0,0 +1,60 @@
+package src
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitWords(t *testing.T) {
+	assert.Equal(t, []string{"order", "items"}, SplitWords("order_items"))
+	assert.Equal(t, []string{"user", "id"}, SplitWords("UserID"))
+	assert.Equal(t, []string{"api", "key"}, SplitWords("APIKey"))
+	assert.Equal(t, []string{"created", "at"}, SplitWords("created-at"))
+}
+
+func TestSingularize(t *testing.T) {
+	assert.Equal(t, "user", Singularize("users"))
+	assert.Equal(t, "category", Singularize("categories"))
+	assert.Equal(t, "address", Singularize("addresses"))
+	assert.Equal(t, "status", Singularize("status"))
+	assert.Equal(t, "person", Singularize("people"))
+}
+
+func TestNamingStrategies(t *testing.T) {
+	legacy := NamingConfig{}
+	assert.Equal(t, "Order_items", legacy.TypeName("order_items"))
+	assert.Equal(t, "Created_at", legacy.FieldName("users", "created_at"))
+	assert.Equal(t, "", legacy.JSONName("created_at"))
+
+	pascal := NamingConfig{Types: NamingPascal, Fields: NamingPascal, JSONTags: NamingCamel, Initialisms: true, Singularize: true}
+	assert.Equal(t, "OrderItem", pascal.TypeName("order_items"))
+	assert.Equal(t, "UserID", pascal.FieldName("users", "user_id"))
+	assert.Equal(t, "APIKey", pascal.FieldName("users", "api_key"))
+	assert.Equal(t, "apiKey", pascal.JSONName("api_key"))
+	assert.Equal(t, "ProductPriceID", pascal.PrefixedFieldName("product_price", "id"))
+
+	camel := NamingConfig{Fields: NamingCamel, Initialisms: true}
+	assert.Equal(t, "userURL", camel.FieldName("users", "user_url"))
+
+	snake := NamingConfig{Fields: NamingSnake, TypePrefix: "Db", TypeSuffix: "Row", Types: NamingPascal}
+	assert.Equal(t, "created_at", snake.FieldName("users", "created_at"))
+	assert.Equal(t, "DbUsersRow", snake.TypeName("users"))
+}
+
+func TestNamingRename(t *testing.T) {
+	naming := NamingConfig{Fields: NamingPascal, Rename: map[string]string{"users": "Account", "users.pw_hash": "PasswordHash", "ts": "Timestamp"}}
+
+	assert.Equal(t, "Account", naming.TypeName("users"))
+	assert.Equal(t, "PasswordHash", naming.FieldName("users", "pw_hash"))
+	assert.Equal(t, "PwHash", naming.FieldName("orders", "pw_hash"))
+	assert.Equal(t, "Timestamp", naming.FieldName("orders", "ts"))
+
+	// an exact match wins over keys differing in case
+	naming.Rename = map[string]string{"Users": "Customer", "users": "Account", "USERS": "Member"}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "Account", naming.TypeName("users"))
+		assert.Equal(t, "Customer", naming.TypeName("Users"))
+		assert.Equal(t, "Member", naming.TypeName("uSers"), "keys are checked in alphabetical order")
+	}
+}
@@ -0,0 +1,205 @@
+package src
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultGoFilePattern is the file name of Go structs if single_file is false, e.g. "users.go"
+	DefaultGoFilePattern = "{table}.go"
+	// DefaultTypeScriptFilePattern is the file name of TypeScript interfaces if single_file is false, e.g. "Users.ts"
+	DefaultTypeScriptFilePattern = "{name}.ts"
+	// TypeScriptIndexFile is the barrel file that re-exports every TypeScript file if single_file is false
+	TypeScriptIndexFile = "index.ts"
+	// GeneratedHeader is the first line of every generated file. It follows the convention of go generate, so linters and reviewers skip the file.
+	// Existing output files are only overwritten if they contain it, see WriteFiles.
+	GeneratedHeader = "// Code generated by sql2interface. DO NOT EDIT."
+)
+
+// NewConvertedStructure creates an empty ConvertedStructure.
+func NewConvertedStructure() *ConvertedStructure {
+	return &ConvertedStructure{
+		StructureDefinition: make(map[string]string),
+		StructureNames:      make(map[string][]string),
+		Structures:          make(map[string][]Structure),
+	}
+}
+
+// Add adds a converted table or combined structure of an output type.
+//
+// Parameters:
+// - target: The output type, "typescript" or "go".
+// - sql: The table definition the structure was created from.
+// - content: The interface or struct created from the definition.
+func (output *ConvertedStructure) Add(target string, sql SQL, content string) {
+	table := sql.OriginalName
+	if table == "" {
+		table = strings.Join(SplitWords(sql.TableName), "_")
+	}
+
+	output.StructureDefinition[target] = output.StructureDefinition[target] + "\n\n" + content
+	output.StructureNames[target] = append(output.StructureNames[target], sql.TableName)
+	output.Structures[target] = append(output.Structures[target], Structure{Name: sql.TableName, Table: table, Content: content})
+}
+
+// WritesSingleFile reports whether all structures of an output type are written into a single output file.
+// This is the default, single_file: false writes one file per table and combined structure.
+func (conf *Config) WritesSingleFile() bool {
+	return conf.SingleFile == nil || *conf.SingleFile
+}
+
+// OutputDirs returns the configured output directories of all output types.
+func (conf *Config) OutputDirs() []string {
+	var dirs []string
+	if conf.Output.TypeScript != nil && strings.TrimSpace(conf.Output.TypeScript.OutputDir) != "" {
+		dirs = append(dirs, conf.Output.TypeScript.OutputDir)
+	}
+	if conf.Output.Go != nil && strings.TrimSpace(conf.Output.Go.OutputDir) != "" {
+		dirs = append(dirs, conf.Output.Go.OutputDir)
+	}
+	return dirs
+}
+
+// FileName returns the name of the file of a single structure by replacing the placeholders {name} (e.g. "Users")
+// and {table} (e.g. "users") in a file name pattern.
+//
+// Parameters:
+// - pattern: The file name pattern, e.g. "{table}.go".
+// - structure: The structure written to the file.
+//
+// Return:
+// - The file name, e.g. "users.go".
+func FileName(pattern string, structure Structure) string {
+	return strings.NewReplacer("{name}", structure.Name, "{table}", structure.Table).Replace(pattern)
+}
+
+// OutputFiles creates the output files of every configured output type for the converted structures of a directory.
+// If single_file is false, every structure is written into its own file named by the file_pattern of the output type
+// and the TypeScript files are re-exported by an index.ts barrel file. If keep_regions is set, every file except the barrel file
+// gets a keep region for imports and one below every structure.
+//
+// Parameters:
+// dir (string): The subdirectory of the output directories the files are written to, or an empty string for the output directories themselves.
+// Go files in a subdirectory belong to a package named after the subdirectory.
+// output (ConvertedStructure): The converted structures.
+//
+// Return:
+// []GeneratedFile: The output files.
+func (s2i *SQL2Interface) OutputFiles(dir string, output ConvertedStructure) []GeneratedFile {
+	var generated []GeneratedFile
+	singleFile := s2i.Config.WritesSingleFile()
+
+	//get options for output
+	if tsOutput := s2i.Config.Output.TypeScript; tsOutput != nil && strings.TrimSpace(tsOutput.OutputDir) != "" {
+		outputDir := filepath.Join(tsOutput.OutputDir, filepath.FromSlash(dir))
+
+		if !singleFile {
+			generated = append(generated, s2i.typeScriptFiles(outputDir, output.Structures["typescript"])...)
+		} else if strings.TrimSpace(tsOutput.OutputFile) != "" {
+			content := s2i.FileContent("typescript", dir, output)
+			generated = append(generated, GeneratedFile{Target: "typescript", Dir: outputDir, Name: tsOutput.OutputFile, Content: content})
+		}
+	}
+
+	if goOutput := s2i.Config.Output.Go; goOutput != nil && strings.TrimSpace(goOutput.OutputDir) != "" {
+		outputDir := filepath.Join(goOutput.OutputDir, filepath.FromSlash(dir))
+		packageName := s2i.goPackageName(dir)
+
+		if !singleFile {
+			pattern := FirstNonEmpty(goOutput.FilePattern, DefaultGoFilePattern)
+			for _, structure := range output.Structures["go"] {
+				content := goFileHeader(packageName) + s2i.importsKeepRegion() + "\n\n" + structure.Content + s2i.structureKeepRegion(structure.Name)
+				generated = append(generated, GeneratedFile{Target: "go", Dir: outputDir, Name: FileName(pattern, structure), Content: content})
+			}
+		} else if strings.TrimSpace(goOutput.OutputFile) != "" {
+			content := s2i.FileContent("go", dir, output)
+			generated = append(generated, GeneratedFile{Target: "go", Dir: outputDir, Name: goOutput.OutputFile, Content: content})
+		}
+	}
+
+	return generated
+}
+
+// FileContent creates the content of a single file containing every converted structure of an output type,
+// including the generated code header, the export statement of TypeScript interfaces if export_types is set, the package clause of Go structs
+// and the keep regions if keep_regions is set.
+//
+// Parameters:
+// - target: The output type, "typescript" or "go".
+// - dir: The subdirectory of the output directory the file is written to, or an empty string for the output directory itself.
+// - output: The converted structures.
+//
+// Return:
+// - The content of the file.
+func (s2i *SQL2Interface) FileContent(target string, dir string, output ConvertedStructure) string {
+	content := output.StructureDefinition[target]
+	if s2i.Config.KeepRegions {
+		content = ""
+		for _, structure := range output.Structures[target] {
+			content += "\n\n" + structure.Content + s2i.structureKeepRegion(structure.Name)
+		}
+	}
+
+	switch target {
+	case "typescript":
+		if tsOutput := s2i.Config.Output.TypeScript; tsOutput != nil && tsOutput.ExportTypes {
+			s2i.AddInterfaceExports(&content, output.StructureNames["typescript"])
+		}
+		content = GeneratedHeader + "\n" + s2i.importsKeepRegion() + content
+	case "go":
+		content = goFileHeader(s2i.goPackageName(dir)) + s2i.importsKeepRegion() + content
+	}
+
+	return content
+}
+
+// goFileHeader returns the beginning of a Go file: the generated code header and the package clause, if the package is known.
+func goFileHeader(packageName string) string {
+	header := GeneratedHeader + "\n"
+	if packageName != "" {
+		header += fmt.Sprintf("\npackage %v\n", packageName)
+	}
+	return header
+}
+
+// goPackageName returns the package of the Go files in a subdirectory of the output directory: the configured package_name
+// for the output directory itself and the name of the subdirectory otherwise.
+// Without a package_name, the package is named after the output directory, so the files always have a package clause.
+func (s2i *SQL2Interface) goPackageName(dir string) string {
+	if dir != "" {
+		return GoPackageName(path.Base(dir))
+	}
+	goOutput := s2i.Config.Output.Go
+	if goOutput == nil {
+		return ""
+	}
+	if goOutput.PackageName == "" && strings.TrimSpace(goOutput.OutputDir) != "" {
+		if base := filepath.Base(filepath.Clean(goOutput.OutputDir)); base != "." && base != string(filepath.Separator) {
+			return GoPackageName(base)
+		}
+	}
+	return goOutput.PackageName
+}
+
+// typeScriptFiles creates one file per TypeScript interface and the index.ts barrel file re-exporting all of them.
+// The interfaces are always exported, so they can be re-exported by the barrel file.
+func (s2i *SQL2Interface) typeScriptFiles(outputDir string, structures []Structure) []GeneratedFile {
+	var generated []GeneratedFile
+	var index strings.Builder
+	pattern := FirstNonEmpty(s2i.Config.Output.TypeScript.FilePattern, DefaultTypeScriptFilePattern)
+
+	for _, structure := range structures {
+		content := structure.Content
+		s2i.AddInterfaceExports(&content, []string{structure.Name})
+		content = GeneratedHeader + "\n" + s2i.importsKeepRegion() + "\n" + content + s2i.structureKeepRegion(structure.Name)
+
+		name := FileName(pattern, structure)
+		generated = append(generated, GeneratedFile{Target: "typescript", Dir: outputDir, Name: name, Content: content})
+		fmt.Fprintf(&index, "export * from './%v';\n", strings.TrimSuffix(name, ".ts"))
+	}
+
+	return append(generated, GeneratedFile{Target: "typescript", Dir: outputDir, Name: TypeScriptIndexFile, Content: GeneratedHeader + "\n\n" + index.String()})
+}
@@ -0,0 +1,54 @@
+package src
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputFilesPerTable(t *testing.T) {
+	singleFile := false
+	s2i := &SQL2Interface{Config: &Config{
+		SingleFile: &singleFile,
+		Output: OutputConfig{
+			TypeScript: &TypeScriptOutput{OutputDir: "ts"},
+			Go:         &GoOutput{OutputDir: "go", PackageName: "models", FilePattern: "{table}_gen.go"},
+		},
+	}}
+
+	output := NewConvertedStructure()
+	users := SQL{TableName: "Users", OriginalName: "users", Columns: []Column{{Name: "Id", Type: "int"}}}
+	products := SQL{TableName: "ProductPrices", Columns: []Column{{Name: "Price", Type: "float32"}}}
+	for _, sql := range []SQL{users, products} {
+		output.Add("typescript", sql, CreateInterface(sql))
+		output.Add("go", sql, CreateStruct(sql))
+	}
+
+	var names []string
+	for _, file := range s2i.OutputFiles("", *output) {
+		names = append(names, file.Path())
+	}
+	assert.Equal(t, []string{"ts/Users.ts", "ts/ProductPrices.ts", "ts/index.ts", "go/users_gen.go", "go/product_prices_gen.go"}, names)
+
+	files := s2i.OutputFiles("", *output)
+	assert.Equal(t, GeneratedHeader+"\n\nexport * from './Users';\nexport * from './ProductPrices';\n", files[2].Content)
+	assert.Contains(t, files[0].Content, "export{\n\tUsers\n}")
+	assert.Equal(t, GeneratedHeader+"\n\npackage models\n\n\n"+CreateStruct(users), files[3].Content)
+}
+
+func TestOutputFilesDefaultPackage(t *testing.T) {
+	singleFile := false
+	s2i := &SQL2Interface{Config: &Config{
+		SingleFile: &singleFile,
+		Output:     OutputConfig{Go: &GoOutput{OutputDir: "internal/db-models"}},
+	}}
+
+	output := NewConvertedStructure()
+	users := SQL{TableName: "Users", OriginalName: "users", Columns: []Column{{Name: "Id", Type: "int"}}}
+	output.Add("go", users, CreateStruct(users))
+
+	// without package_name the files are in the package named after the output directory
+	files := s2i.OutputFiles("", *output)
+	assert.Len(t, files, 1)
+	assert.Equal(t, GeneratedHeader+"\n\npackage db_models\n\n\n"+CreateStruct(users), files[0].Content)
+}
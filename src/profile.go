@@ -0,0 +1,207 @@
+package src
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of environment variables that override configuration values.
+const EnvPrefix = "S2I_"
+
+// ConfigOverlay is the content of a profile overlay file like s2iconfig.dev.yaml.
+type ConfigOverlay struct {
+	File    string
+	Content []byte
+}
+
+// ProfileOverlayPath returns the path of the overlay file of a profile, e.g. "config/s2iconfig.dev.yaml" for "config/s2iconfig.yaml".
+//
+// configPath: The path of the configuration file.
+// profile: The name of the profile.
+//
+// Returns:
+// - The path of the overlay file next to the configuration file.
+func ProfileOverlayPath(configPath string, profile string) string {
+	extension := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, extension) + "." + profile + extension
+}
+
+// MergeConfigNodes deep-merges an overlay mapping node into a base mapping node.
+// Keys of nested mappings are merged recursively, all other values (including lists) are replaced by the overlay.
+//
+// base: The mapping node that is modified.
+// overlay: The mapping node that is merged into base.
+func MergeConfigNodes(base *yaml.Node, overlay *yaml.Node) {
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		*base = *overlay
+		return
+	}
+
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		if key.Value == "profiles" {
+			continue
+		}
+
+		if existing := mappingValue(base, key.Value); existing != nil && existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			MergeConfigNodes(existing, value)
+		} else if existing != nil {
+			*existing = *value
+		} else {
+			base.Content = append(base.Content, key, value)
+		}
+	}
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil if the node is not a mapping or has no such key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// EnvOverrides converts S2I_* environment variables into overrides for ApplyConfigOverrides.
+// Nested keys are separated by a double underscore, e.g. S2I_OUTPUT__GO__PACKAGE_NAME=models
+// becomes "output.go.package_name=models". S2I_PROFILE selects the profile and is not an override.
+// Variables that do not name a configuration key (see KnownConfigKey) are skipped, so S2I_* variables
+// can still be used for other purposes like the expansion of paths.
+//
+// environ: The environment in the format of os.Environ.
+//
+// Returns:
+// - The overrides in the format "key=value".
+func EnvOverrides(environ []string) []string {
+	var overrides []string
+
+	for _, variable := range environ {
+		if _, override, isOverride := envOverride(variable); isOverride {
+			overrides = append(overrides, override)
+		}
+	}
+
+	return overrides
+}
+
+// IgnoredEnvVariables returns the names of the S2I_* environment variables that are skipped by EnvOverrides
+// because they do not name a configuration key, e.g. to report misspelled keys.
+//
+// environ: The environment in the format of os.Environ.
+//
+// Returns:
+// - The names of the skipped variables.
+func IgnoredEnvVariables(environ []string) []string {
+	var ignored []string
+
+	for _, variable := range environ {
+		name, _, _ := strings.Cut(variable, "=")
+		if _, _, isOverride := envOverride(variable); !isOverride && strings.HasPrefix(name, EnvPrefix) && name != EnvPrefix+"PROFILE" {
+			ignored = append(ignored, name)
+		}
+	}
+
+	return ignored
+}
+
+// ApplyEnvOverrides applies the overrides of the S2I_* environment variables (see EnvOverrides) to a configuration.
+//
+// conf: The configuration to be modified.
+// environ: The environment in the format of os.Environ.
+//
+// Returns:
+// - An error containing the name of the variable if its value does not fit the configuration.
+func ApplyEnvOverrides(conf *Config, environ []string) error {
+	for _, variable := range environ {
+		name, override, isOverride := envOverride(variable)
+		if !isOverride {
+			continue
+		}
+
+		if overrideError := ApplyConfigOverrides(conf, []string{override}); overrideError != nil {
+			return fmt.Errorf("environment variable %v: %w", name, overrideError)
+		}
+	}
+
+	return nil
+}
+
+// envOverride converts a single environment variable into an override, see EnvOverrides.
+func envOverride(variable string) (string, string, bool) {
+	name, value, found := strings.Cut(variable, "=")
+	if !found || !strings.HasPrefix(name, EnvPrefix) || name == EnvPrefix+"PROFILE" {
+		return name, "", false
+	}
+
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(name, EnvPrefix), "__", "."))
+	if !KnownConfigKey(key) {
+		return name, "", false
+	}
+
+	return name, key + "=" + value, true
+}
+
+// KnownConfigKey reports whether a key in the format of ApplyConfigOverrides names a value of the configuration,
+// e.g. "output.go.package_name" or "ignore_columns[users.sql]". Keys of maps like ignore_columns can be any name.
+//
+// key: The key of the configuration value.
+//
+// Returns:
+// - Whether the key can be set.
+func KnownConfigKey(key string) bool {
+	segments := SplitConfigKey(key)
+	if len(segments) == 0 {
+		return false
+	}
+
+	current := reflect.TypeOf(Config{})
+	for _, segment := range segments {
+		for current.Kind() == reflect.Pointer {
+			current = current.Elem()
+		}
+
+		switch {
+		case current == reflect.TypeOf(yaml.Node{}):
+			return false
+		case current.Kind() == reflect.Struct:
+			field, found := configField(current, segment)
+			if !found {
+				return false
+			}
+			current = field.Type
+		case current.Kind() == reflect.Map:
+			current = current.Elem()
+		default:
+			return false
+		}
+	}
+
+	return current != reflect.TypeOf(yaml.Node{}) && current != reflect.TypeOf(map[string]yaml.Node{})
+}
+
+// configField returns the field of a configuration struct with the given YAML key.
+func configField(structType reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if field.IsExported() && name == key {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// EnvProfile returns the profile selected with the S2I_PROFILE environment variable.
+func EnvProfile() string {
+	return os.Getenv(EnvPrefix + "PROFILE")
+}
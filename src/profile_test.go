@@ -0,0 +1,57 @@
+package src
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeConfigProfile(t *testing.T) {
+	content := "input: ./sql\noutput:\n  go:\n    output_dir: ./out\n    output_file: types.go\n" +
+		"profiles:\n  prod:\n    input: ./prod_sql\n    output:\n      go:\n        output_dir: ./prod_out\n"
+	overlay := &ConfigOverlay{File: "s2iconfig.prod.yaml", Content: []byte("output:\n  go:\n    package_name: prod\n")}
+
+	conf, err := DecodeConfigProfile("s2iconfig.yaml", []byte(content), "prod", overlay)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "./prod_sql", conf.Input)
+	assert.Equal(t, GoOutput{OutputDir: "./prod_out", OutputFile: "types.go", PackageName: "prod"}, *conf.Output.Go)
+
+	_, err = DecodeConfigProfile("s2iconfig.yaml", []byte(content), "dev", nil)
+	assert.EqualError(t, err, "s2iconfig.yaml:7:3: profile 'dev' not found")
+}
+
+func TestEnvOverrides(t *testing.T) {
+	environ := []string{"S2I_INPUT=./sql", "S2I_OUTPUT__GO__PACKAGE_NAME=models", "S2I_PROFILE=prod", "HOME=/root", "S2I_SCHEMA=x", "S2I_OUTPUT__GO__PACKAGE=x", "S2I_IGNORE_COLUMNS__USERS=[id]"}
+	overrides := EnvOverrides(environ)
+
+	assert.Equal(t, []string{"input=./sql", "output.go.package_name=models", "ignore_columns.users=[id]"}, overrides)
+	assert.Equal(t, []string{"S2I_SCHEMA", "S2I_OUTPUT__GO__PACKAGE"}, IgnoredEnvVariables(environ))
+	assert.Equal(t, "s2i/s2iconfig.dev.yaml", ProfileOverlayPath("s2i/s2iconfig.yaml", "dev"))
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	conf := &Config{Output: OutputConfig{Go: &GoOutput{}}}
+	assert.NoError(t, ApplyEnvOverrides(conf, []string{"S2I_OUTPUT__GO__PACKAGE_NAME=models", "S2I_TEST_SCHEMA=schema"}))
+	assert.Equal(t, "models", conf.Output.Go.PackageName)
+
+	err := ApplyEnvOverrides(conf, []string{"S2I_SINGLE_FILE=maybe"})
+	assert.EqualError(t, err, "environment variable S2I_SINGLE_FILE: invalid override: cannot unmarshal !!str `maybe` into bool")
+}
+
+func TestKnownConfigKey(t *testing.T) {
+	for key, known := range map[string]bool{
+		"input":                     true,
+		"output.go.package_name":    true,
+		"output.typescript":         true,
+		"ignore_columns[users.sql]": true,
+		"naming.go.escape":          true,
+		"schema":                    false,
+		"output.go.package":         false,
+		"input.path":                false,
+		"profiles.dev":              false,
+		"":                          false,
+	} {
+		assert.Equal(t, known, KnownConfigKey(key), key)
+	}
+}
@@ -0,0 +1,192 @@
+package src
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// escape modes for identifiers that are reserved words or otherwise invalid in the target language
+const (
+	EscapeModeSuffix = "suffix"
+	EscapeModePrefix = "prefix"
+	// EscapeModeQuote writes the field name as a string literal. Only supported for typescript fields, where it is the default.
+	EscapeModeQuote = "quote"
+)
+
+// GoKeywords contains the reserved keywords of Go.
+var GoKeywords = []string{
+	"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func", "go", "goto",
+	"if", "import", "interface", "map", "package", "range", "return", "select", "struct", "switch", "type", "var",
+}
+
+// TypeScriptKeywords contains the reserved words of TypeScript including the names of its predefined types.
+// Reserved words are valid property names in TypeScript, so they are only checked for interface names.
+var TypeScriptKeywords = []string{
+	"break", "case", "catch", "class", "const", "continue", "debugger", "default", "delete", "do", "else", "enum",
+	"export", "extends", "false", "finally", "for", "function", "if", "import", "in", "instanceof", "new", "null",
+	"return", "super", "switch", "this", "throw", "true", "try", "typeof", "var", "void", "while", "with", "implements",
+	"interface", "let", "package", "private", "protected", "public", "static", "yield", "any", "boolean", "number",
+	"string", "symbol", "never", "unknown", "object", "undefined", "type",
+}
+
+// IsReservedWord checks if a name is a reserved word in the given output type.
+//
+// Parameters:
+// - definitionType: The output type ("go" or "typescript").
+// - name: The name to be checked.
+//
+// Return:
+// - A boolean value indicating whether the name is a reserved word.
+func IsReservedWord(definitionType string, name string) bool {
+	keywords := GoKeywords
+	if definitionType == "typescript" {
+		keywords = TypeScriptKeywords
+	}
+
+	for _, keyword := range keywords {
+		if keyword == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidIdentifier checks if a name matches the identifier grammar of the given output type.
+// Identifiers start with a letter or an underscore followed by letters, digits and underscores. TypeScript additionally allows "$".
+//
+// Parameters:
+// - definitionType: The output type ("go" or "typescript").
+// - name: The name to be checked.
+//
+// Return:
+// - A boolean value indicating whether the name is a valid identifier.
+func IsValidIdentifier(definitionType string, name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		valid := unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r)) || (definitionType == "typescript" && r == '$')
+		if !valid {
+			return false
+		}
+	}
+	return true
+}
+
+// SanitizeIdentifier converts a name into a valid identifier for the given output type.
+// Invalid characters are replaced by underscores, names starting with a digit get the escape prefix
+// and reserved words are escaped with the configured escape mode. Typescript field names are only checked
+// against the identifier grammar and are written as string literals unless the escape mode is "prefix" or "suffix",
+// because renaming a property changes the key values are deserialized into.
+//
+// Parameters:
+// - definitionType: The output type ("go" or "typescript").
+// - name: The name to be sanitized.
+// - isType: Indicates whether the name is the name of an interface or struct (true) or of a field (false).
+// - naming: The naming configuration containing the escape settings.
+//
+// Return:
+// - string: The sanitized name.
+// - bool: Indicates whether the name had to be changed.
+func SanitizeIdentifier(definitionType string, name string, isType bool, naming NamingConfig) (string, bool) {
+	checkReserved := isType || definitionType != "typescript"
+
+	if IsValidIdentifier(definitionType, name) && (!checkReserved || !IsReservedWord(definitionType, name)) {
+		return name, false
+	}
+
+	if definitionType == "typescript" && !isType && (naming.Escape == "" || naming.Escape == EscapeModeQuote) {
+		return fmt.Sprintf("%q", name), true
+	}
+
+	prefix := FirstNonEmpty(naming.EscapePrefix, "X")
+	suffix := FirstNonEmpty(naming.EscapeSuffix, "_")
+
+	if !IsValidIdentifier(definitionType, name) {
+		name = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || (definitionType == "typescript" && r == '$') {
+				return r
+			}
+			return '_'
+		}, name)
+
+		if name == "" || unicode.IsDigit([]rune(name)[0]) {
+			name = prefix + name
+		}
+	}
+
+	if checkReserved && IsReservedWord(definitionType, name) {
+		if naming.Escape == EscapeModePrefix {
+			name = prefix + name
+		} else {
+			name = name + suffix
+		}
+	}
+
+	return name, true
+}
+
+// SanitizeStructure makes the structure name and all field names of an SQL table definition valid identifiers for the given output type.
+// If a Go field is renamed, it gets a json tag with its original name so the serialization key does not change.
+//
+// Parameters:
+// - definitionType: The output type ("go" or "typescript").
+// - sql: A pointer to the SQL table definition to be sanitized.
+func (s2i *SQL2Interface) SanitizeStructure(definitionType string, sql *SQL) {
+	naming := s2i.Naming(definitionType)
+	// the columns are shared with the table definitions collected for combined structures, which are sanitized on their own
+	sql.Columns = append([]Column(nil), sql.Columns...)
+
+	if sanitized, changed := SanitizeIdentifier(definitionType, sql.TableName, true, naming); changed {
+		s2i.logger().Info("structure renamed", "structure", sql.TableName, "name", sanitized, "target", definitionType)
+		sql.TableName = sanitized
+	}
+
+	for i, column := range sql.Columns {
+		sanitized, changed := SanitizeIdentifier(definitionType, column.Name, false, naming)
+		if !changed {
+			continue
+		}
+
+		s2i.logger().Info("field renamed", "structure", sql.TableName, "field", column.Name, "name", sanitized, "target", definitionType)
+
+		if definitionType == "go" {
+			if _, hasJSONTag := column.Tags["json"]; !hasJSONTag {
+				tags := map[string]string{"json": FirstNonEmpty(column.OriginalName, column.Name)}
+				for key, value := range column.Tags {
+					tags[key] = value
+				}
+				sql.Columns[i].Tags = tags
+			}
+		}
+
+		sql.Columns[i].Name = sanitized
+	}
+}
+
+// GoPackageName converts a directory name into a valid Go package name: lower case letters, digits and underscores,
+// not starting with a digit and not a keyword.
+//
+// Parameters:
+// - dir: The name of the directory.
+//
+// Return:
+// - The package name.
+func GoPackageName(dir string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(dir) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			builder.WriteRune(r)
+		} else {
+			builder.WriteRune('_')
+		}
+	}
+
+	name := builder.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) || IsReservedWord("go", name) {
+		name = "pkg_" + name
+	}
+	return name
+}
@@ -0,0 +1,91 @@
+package src
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeIdentifier(t *testing.T) {
+	name, changed := SanitizeIdentifier("go", "type", false, NamingConfig{})
+	assert.True(t, changed)
+	assert.Equal(t, "type_", name)
+
+	name, _ = SanitizeIdentifier("go", "default", false, NamingConfig{Escape: EscapeModePrefix, EscapePrefix: "F"})
+	assert.Equal(t, "Fdefault", name)
+
+	name, _ = SanitizeIdentifier("go", "2fa_enabled", false, NamingConfig{})
+	assert.Equal(t, "X2fa_enabled", name)
+
+	name, changed = SanitizeIdentifier("typescript", "class", false, NamingConfig{})
+	assert.False(t, changed)
+	assert.Equal(t, "class", name)
+
+	name, _ = SanitizeIdentifier("typescript", "interface", true, NamingConfig{})
+	assert.Equal(t, "interface_", name)
+
+	name, _ = SanitizeIdentifier("typescript", "first name", false, NamingConfig{Escape: EscapeModeQuote})
+	assert.Equal(t, `"first name"`, name)
+
+	// typescript fields keep their key unless renaming is configured
+	name, _ = SanitizeIdentifier("typescript", "2fa_enabled", false, NamingConfig{})
+	assert.Equal(t, `"2fa_enabled"`, name)
+
+	name, _ = SanitizeIdentifier("typescript", "first-name", false, NamingConfig{Escape: EscapeModeSuffix})
+	assert.Equal(t, "first_name", name)
+}
+
+func TestSanitizeStructureKeepsOriginalNameInTags(t *testing.T) {
+	s2i := &SQL2Interface{Config: &Config{}}
+	sql := SQL{TableName: "Users", Columns: []Column{{Name: "type", OriginalName: "type", Type: "string"}}}
+
+	s2i.SanitizeStructure("go", &sql)
+
+	assert.Equal(t, "type_", sql.Columns[0].Name)
+	assert.Equal(t, map[string]string{"json": "type"}, sql.Columns[0].Tags)
+}
+
+func TestRenderQuotedIdentifiers(t *testing.T) {
+	tables, err := ParseDDL(strings.NewReader("CREATE TABLE `interface` (\n"+
+		"  `type` INT, -- reserved word\n"+
+		"  `2fa_enabled` BOOL,\n"+
+		"  `first name` TEXT,\n"+
+		"  \"class\" TEXT,\n"+
+		"  `user-id` INT\n"+
+		");"), DDLOptions{FileName: "interface.sql"})
+	assert.NoError(t, err)
+	assert.Equal(t, "interface", tables[0].OriginalName)
+	assert.Equal(t, "reserved word", tables[0].Columns[0].Comment)
+
+	content, err := Render(tables, "go", &Config{Output: OutputConfig{Go: &GoOutput{PackageName: "models"}}})
+	assert.NoError(t, err)
+
+	file, parseError := parser.ParseFile(token.NewFileSet(), "models.go", content, 0)
+	if !assert.NoError(t, parseError, string(content)) {
+		return
+	}
+
+	var jsonNames []string
+	ast.Inspect(file, func(node ast.Node) bool {
+		field, isField := node.(*ast.Field)
+		if !isField {
+			return true
+		}
+		assert.True(t, field.Names[0].IsExported(), field.Names[0].Name)
+		if field.Tag != nil {
+			tag, _ := strconv.Unquote(field.Tag.Value)
+			jsonNames = append(jsonNames, reflect.StructTag(tag).Get("json"))
+		}
+		return false
+	})
+	assert.Equal(t, []string{"2fa_enabled", "first name", "user-id"}, jsonNames)
+
+	content, _ = Render(tables, "typescript", nil)
+	assert.Contains(t, string(content), "interface Interface {\n\t/** reserved word */\r\n\tType: Number, \r\n\t\"2Fa_enabled\": Boolean, \r\n\t\"First Name\": String, \r\n\tClass: String, \r\n\t\"User-Id\": Number\r\n}")
+}
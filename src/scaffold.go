@@ -0,0 +1,210 @@
+package src
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AuditColumns contains column names that are commonly used for auditing and are suggested for ignore_columns.
+var AuditColumns = []string{"created_at", "updated_at", "deleted_at", "created_by", "updated_by", "deleted_by"}
+
+// ScannedTable is a table discovered while scanning a directory of SQL files.
+type ScannedTable struct {
+	FileName     string
+	Dir          string
+	TableName    string
+	Columns      []string
+	AuditColumns []string
+}
+
+// ScanTables parses every file of a directory and its subdirectories that is found by FindInputFiles and returns the tables it contains.
+// Every CREATE TABLE statement of a file is scanned, see ParseStatements. Files that do not contain a CREATE TABLE statement are skipped.
+//
+// Parameters:
+// - dir: The directory containing the SQL files.
+// - options: The extensions of the scanned files and the handling of symlinks.
+//
+// Return:
+// - []ScannedTable: The discovered tables in the order of their files.
+// - error: An error if the directory could not be read.
+func ScanTables(dir string, options InputOptions) ([]ScannedTable, error) {
+	files, readError := FindInputFiles(dir, options)
+	if readError != nil {
+		return nil, readError
+	}
+
+	s2i := &SQL2Interface{Config: &Config{}}
+	var tables []ScannedTable
+
+	for _, file := range files {
+		content, contentError := file.ReadFile()
+		if contentError != nil {
+			continue
+		}
+
+		// statements that could not be parsed are skipped, the tables of the file are scanned nevertheless
+		parsed, _ := s2i.ParseStatements(file.Name, string(content))
+
+		for _, sql := range parsed {
+			table := ScannedTable{FileName: file.Name, Dir: file.Dir(), TableName: sql.OriginalName}
+			for _, column := range sql.Columns {
+				table.Columns = append(table.Columns, column.OriginalName)
+				for _, auditColumn := range AuditColumns {
+					if column.OriginalName == auditColumn {
+						table.AuditColumns = append(table.AuditColumns, column.OriginalName)
+					}
+				}
+			}
+
+			tables = append(tables, table)
+		}
+	}
+
+	return tables, nil
+}
+
+// ScaffoldConfig creates the content of a commented s2iconfig.yaml that lists every supported option.
+// If tables are given, they are listed in the file and their audit columns are suggested for ignore_columns.
+//
+// Parameters:
+// - input: The input directory written to the configuration.
+// - tables: The tables discovered in the input directory, may be empty.
+//
+// Return:
+// - string: The content of the configuration file.
+func ScaffoldConfig(input string, tables []ScannedTable) string {
+	var builder strings.Builder
+
+	builder.WriteString("# s2iconfig.yaml - configuration of sql2interface\n\n")
+	builder.WriteString("# directory containing the sql files with the CREATE TABLE statements\n")
+	fmt.Fprintf(&builder, "input: %q\n\n", input)
+	builder.WriteString(`# subdirectories of the input directory are converted as well.
+# extensions of the converted files
+# include_extensions: [".sql"]
+# walk into symlinked directories
+# follow_symlinks: false
+# write the structures of every input subdirectory into the same subdirectory of the output directories
+# mirror_input_dirs: false
+# convert the tables of a JSON file written by s2i dump instead of the input directory
+# input_schema: "./schema.json"
+
+`)
+
+	if len(tables) > 0 {
+		builder.WriteString("# discovered tables:\n")
+		for _, table := range tables {
+			fmt.Fprintf(&builder, "#   %v (%v): %v\n", table.TableName, table.FileName, strings.Join(table.Columns, ", "))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString(`# output types. If an output type is missing, no file is generated for it
+output:
+  typescript:
+    output_dir: "./output"
+    output_file: "Types.ts"
+    # add an export statement for all interfaces
+    export_types: true
+    # file name of every interface if single_file is false
+    # file_pattern: "{name}.ts"
+  go:
+    # within go generate, output_dir defaults to the directory of the file and package_name to its package if output_dir is that directory
+    output_dir: "./output"
+    output_file: "types.go"
+    package_name: "main"
+    # file name of every struct if single_file is false
+    # file_pattern: "{table}.go"
+
+# files that are not converted: names, glob patterns or regular expressions in slashes.
+# rules starting with ! include files again, the last matching rule wins
+# ignore_files:
+#   - warehouses.sql
+#   - "*_backup.sql"
+#   - "!users_backup.sql"
+
+`)
+
+	builder.WriteString("# columns that are not converted, per file, table or pattern (\"*\" for every table).\n")
+	builder.WriteString("# columns can be names, glob patterns or regular expressions in slashes, rules starting with ! include columns again\n")
+	var suggestions []ScannedTable
+	for _, table := range tables {
+		if len(table.AuditColumns) > 0 {
+			suggestions = append(suggestions, table)
+		}
+	}
+
+	if len(suggestions) > 0 {
+		builder.WriteString("# suggested audit columns, uncomment to ignore them\n")
+		builder.WriteString("# ignore_columns:\n")
+		for _, table := range suggestions {
+			fmt.Fprintf(&builder, "#   %v:\n", table.FileName)
+			for _, column := range table.AuditColumns {
+				fmt.Fprintf(&builder, "#     - %v\n", column)
+			}
+		}
+	} else {
+		builder.WriteString("# ignore_columns:\n#   \"*\":\n#     - deleted_at\n#   users.sql:\n#     - created_at\n#     - \"/^tmp_/\"\n")
+	}
+
+	builder.WriteString(`
+# tables that are combined into a single interface or struct.
+# tables can be file names, table names or glob patterns
+# combine_tables:
+#   Products:
+#     name: "Products"
+#     tables: ["product", "product_price*"]
+#     # also convert the tables on their own
+#     convert_single_tables: false
+#     # columns in more than one table: error, keep_first, keep_last or prefix
+#     on_collision: error
+
+# additional fields per file, table, combined structure or glob pattern
+# arbitrary_fields:
+#   users:
+#     orders:
+#       name: "Orders"
+#       type_go: "[]Order"
+#       type_ts: "Order[]"
+#       # start, end or after:<column>
+#       position: "end"
+#       optional: true
+#       comment: "orders of the user"
+#       tags:
+#         json: "orders,omitempty"
+
+# naming of types, fields and json tags per output type
+# naming:
+#   go:
+#     # pascal, camel or snake
+#     types: pascal
+#     fields: pascal
+#     # pascal, camel, snake or original
+#     json_tags: snake
+#     # UserID instead of UserId
+#     initialisms: true
+#     extra_initialisms: []
+#     # users becomes User
+#     singularize: true
+#     type_prefix: ""
+#     type_suffix: ""
+#     field_prefix: ""
+#     field_suffix: ""
+#     rename:
+#       users.pw_hash: PasswordHash
+#     # escaping of reserved words: suffix, prefix or quote (typescript fields only, their default)
+#     escape: suffix
+#     escape_prefix: "X"
+#     escape_suffix: "_"
+
+# write all structures into a single output file. If false, every table and combined structure is written
+# into its own file named by output.<type>.file_pattern ({name} e.g. Users, {table} e.g. users),
+# typescript files are re-exported by an index.ts and go files share the package_name
+# single_file: true
+
+# write empty "// s2i:keep-begin <name>" and "// s2i:keep-end" regions for imports and below every structure.
+# code written into them is kept when the files are generated again
+# keep_regions: false
+`)
+
+	return builder.String()
+}
@@ -0,0 +1,28 @@
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaffoldConfig(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "users.sql"), []byte("CREATE TABLE users (id INT, name TEXT, created_at TIMESTAMP)"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# schema"), 0644))
+
+	tables, err := ScanTables(dir, InputOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []ScannedTable{{FileName: "users.sql", TableName: "users", Columns: []string{"id", "name", "created_at"}, AuditColumns: []string{"created_at"}}}, tables)
+
+	content := ScaffoldConfig(dir, tables)
+	assert.Contains(t, content, "#   users (users.sql): id, name, created_at")
+	assert.Contains(t, content, "#   users.sql:\n#     - created_at\n")
+
+	conf, err := DecodeConfig("s2iconfig.yaml", []byte(content))
+	assert.NoError(t, err)
+	assert.Equal(t, dir, conf.Input)
+	assert.Equal(t, "types.go", conf.Output.Go.OutputFile)
+}
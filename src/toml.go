@@ -0,0 +1,182 @@
+package src
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// tomlConverter converts the expressions of a TOML document into yaml nodes, so TOML configurations are decoded and validated like YAML configurations.
+// The document is validated by go-toml before, so the expressions are expected to be valid.
+type tomlConverter struct {
+	parser *unstable.Parser
+}
+
+// ParseTOML parses the content of a TOML configuration file into a yaml mapping node with the line and column of every key and value.
+//
+// fileName: The name of the configuration file used in error messages.
+// content: The content of the configuration file.
+//
+// Returns:
+// - The mapping node of the root table.
+// - A ConfigError if the content is not valid TOML.
+func ParseTOML(fileName string, content []byte) (*yaml.Node, error) {
+	var document map[string]interface{}
+	if decodeError := toml.Unmarshal(content, &document); decodeError != nil {
+		var tomlError *toml.DecodeError
+		if errors.As(decodeError, &tomlError) {
+			line, column := tomlError.Position()
+			return nil, ConfigError{File: fileName, Line: line, Column: column, Message: strings.TrimPrefix(tomlError.Error(), "toml: ")}
+		}
+		return nil, ConfigError{File: fileName, Message: decodeError.Error()}
+	}
+
+	converter := &tomlConverter{parser: &unstable.Parser{}}
+	converter.parser.Reset(content)
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	table := root
+
+	for converter.parser.NextExpression() {
+		expression := converter.parser.Expression()
+
+		switch expression.Kind {
+		case unstable.Table:
+			table = converter.table(root, expression.Key(), false)
+		case unstable.ArrayTable:
+			table = converter.table(root, expression.Key(), true)
+		case unstable.KeyValue:
+			converter.keyValue(table, expression)
+		}
+	}
+
+	if parseError := converter.parser.Error(); parseError != nil {
+		return nil, ConfigError{File: fileName, Message: parseError.Error()}
+	}
+
+	return root, nil
+}
+
+// table returns the table of a [table] or [[array of tables]] header, creating the missing tables along the way.
+// For an array of tables a new table is appended to the array.
+func (converter *tomlConverter) table(root *yaml.Node, keys unstable.Iterator, isArray bool) *yaml.Node {
+	table := root
+
+	for keys.Next() {
+		key := keys.Node()
+		last := keys.IsLast()
+
+		value := mappingValue(table, string(key.Data))
+		if value == nil {
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if last && isArray {
+				value.Kind, value.Tag = yaml.SequenceNode, "!!seq"
+			}
+			converter.add(table, key, value)
+		}
+
+		if last && isArray {
+			element := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			element.Line, element.Column = converter.position(key)
+			value.Content = append(value.Content, element)
+			return element
+		}
+
+		// keys of an array of tables refer to its last table
+		if value.Kind == yaml.SequenceNode && len(value.Content) > 0 {
+			value = value.Content[len(value.Content)-1]
+		}
+		table = value
+	}
+
+	return table
+}
+
+// keyValue adds a key = value pair to a table. The tables of a dotted key are created if they are missing.
+func (converter *tomlConverter) keyValue(table *yaml.Node, expression *unstable.Node) {
+	keys := expression.Key()
+
+	for keys.Next() {
+		key := keys.Node()
+		if keys.IsLast() {
+			converter.add(table, key, converter.value(expression.Value()))
+			return
+		}
+
+		value := mappingValue(table, string(key.Data))
+		if value == nil {
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			converter.add(table, key, value)
+		}
+		table = value
+	}
+}
+
+// add adds a key with its value to a table. Values without a position get the position of the key.
+func (converter *tomlConverter) add(table *yaml.Node, key *unstable.Node, value *yaml.Node) {
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(key.Data)}
+	keyNode.Line, keyNode.Column = converter.position(key)
+
+	if value.Line == 0 {
+		value.Line, value.Column = keyNode.Line, keyNode.Column
+	}
+	table.Content = append(table.Content, keyNode, value)
+}
+
+// value converts a TOML value including all nested values.
+func (converter *tomlConverter) value(value *unstable.Node) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: string(value.Data)}
+	node.Line, node.Column = converter.position(value)
+
+	switch value.Kind {
+	case unstable.Array:
+		node.Kind, node.Tag, node.Value = yaml.SequenceNode, "!!seq", ""
+		items := value.Children()
+		for items.Next() {
+			node.Content = append(node.Content, converter.value(items.Node()))
+		}
+	case unstable.InlineTable:
+		node.Kind, node.Tag, node.Value = yaml.MappingNode, "!!map", ""
+		pairs := value.Children()
+		for pairs.Next() {
+			converter.keyValue(node, pairs.Node())
+		}
+	case unstable.String:
+		node.Tag, node.Style = "!!str", yaml.DoubleQuotedStyle
+	case unstable.Bool:
+		node.Tag = "!!bool"
+	case unstable.Integer:
+		// go-toml has validated the integer, the prefixes 0x, 0o and 0b and underscores are those of Go
+		integer, _ := strconv.ParseInt(node.Value, 0, 64)
+		node.Tag, node.Value = "!!int", strconv.FormatInt(integer, 10)
+	case unstable.Float:
+		float, _ := strconv.ParseFloat(strings.ReplaceAll(node.Value, "_", ""), 64)
+		node.Tag = "!!float"
+		switch {
+		case math.IsNaN(float):
+			node.Value = ".nan"
+		case math.IsInf(float, 1):
+			node.Value = ".inf"
+		case math.IsInf(float, -1):
+			node.Value = "-.inf"
+		default:
+			node.Value = strconv.FormatFloat(float, 'g', -1, 64)
+		}
+	default:
+		// dates and times are not used by the configuration and kept as written
+		node.Tag = "!!str"
+	}
+
+	return node
+}
+
+// position returns the line and column a node starts at.
+func (converter *tomlConverter) position(node *unstable.Node) (int, int) {
+	start := converter.parser.Shape(node.Raw).Start
+	return start.Line, start.Column
+}
@@ -1,5 +1,12 @@
 package src
 
+import (
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
 // ValueInSlice checks if a given value exists in a slice of empty interfaces.//+
 // //+
 // The function iterates over each element in the provided slice and compares it with the given value.//+
@@ -53,3 +60,71 @@ func AddTabToSlice(values *[]string) {
 		(*values)[i] = "\t" + item
 	}
 }
+
+// ToPascalCase converts a snake_case or space separated name into PascalCase.
+//
+// Parameters:
+// - value: The name to be converted, e.g. "product_price".
+//
+// Return:
+// - The converted name, e.g. "ProductPrice".
+func ToPascalCase(value string) string {
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == '_' || r == '-' || unicode.IsSpace(r)
+	})
+
+	for i, part := range parts {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		parts[i] = string(runes)
+	}
+
+	return strings.Join(parts, "")
+}
+
+// MatchPattern checks if a value matches a pattern case-insensitively.
+// The pattern can either be the exact value or a glob pattern as understood by path.Match (e.g. "product_*").
+//
+// Parameters:
+// - pattern: The exact value or glob pattern.
+// - value: The value to be checked.
+//
+// Return:
+// - A boolean value indicating whether the value matches the pattern.
+func MatchPattern(pattern string, value string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	value = strings.ToLower(value)
+
+	if pattern == value {
+		return true
+	}
+
+	matched, matchError := path.Match(pattern, value)
+	return matchError == nil && matched
+}
+
+// SortedKeys returns the keys of a map with string keys in alphabetical order.
+//
+// Parameters:
+// - values: The map whose keys are returned.
+//
+// Return:
+// - A sorted slice containing the keys of the map.
+func SortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FirstNonEmpty returns the first of the given values that is not empty or an empty string if all values are empty.
+func FirstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -21,3 +21,11 @@ func TestValueInSlice(t *testing.T) {
 
 	assert.Equal(t, expected, actual)
 }
+
+func TestMatchPattern(t *testing.T) {
+	assert.True(t, MatchPattern("product_price.sql", "Product_Price.sql"))
+	assert.True(t, MatchPattern("product_*", "Product_price"))
+	assert.True(t, MatchPattern("*.sql", "users.sql"))
+	assert.False(t, MatchPattern("product_*", "products"))
+	assert.False(t, MatchPattern("[", "["+"x"))
+}
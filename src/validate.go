@@ -0,0 +1,601 @@
+package src
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is a position within a configuration file.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// ConfigError is a problem with the configuration, located at a line and column of the configuration file if known.
+type ConfigError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (configError ConfigError) Error() string {
+	location := configError.File
+	if configError.Line > 0 {
+		location = fmt.Sprintf("%v:%v:%v", location, configError.Line, configError.Column)
+	}
+
+	if location == "" {
+		return configError.Message
+	}
+	return location + ": " + configError.Message
+}
+
+// ConfigErrors contains every problem found in a configuration so they can be reported together.
+type ConfigErrors []ConfigError
+
+func (configErrors ConfigErrors) Error() string {
+	var messages []string
+	for _, configError := range configErrors {
+		messages = append(messages, configError.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// yamlLinePattern extracts the line number from error messages of the yaml package.
+var yamlLinePattern = regexp.MustCompile(`line (\d+): `)
+
+// DecodeConfig strictly decodes the content of a YAML, JSON or TOML configuration file. The format is detected by the extension of the file name.
+// Unknown keys are reported with their line and column and a suggestion for the most similar known key,
+// values of the wrong type are reported with their line. All problems are returned together as ConfigErrors.
+// If the content is valid in the format of the file, the configuration is returned even if there are problems, so it can still be validated
+// with Config.Validate and all problems can be reported at once.
+//
+// Parameters:
+// - fileName: The name of the configuration file used in error messages.
+// - content: The content of the configuration file.
+//
+// Return:
+// - *Config: The decoded configuration, or nil if the content could not be parsed.
+// - error: ConfigErrors containing every problem found, or nil if the configuration could be decoded.
+func DecodeConfig(fileName string, content []byte) (*Config, error) {
+	return DecodeConfigProfile(fileName, content, "", nil)
+}
+
+// DecodeConfigProfile decodes the content of a configuration file like DecodeConfig and deep-merges a profile over it.
+// The profile is taken from the profiles section of the configuration and from an optional overlay file, which is merged last.
+// Mappings are merged key by key, all other values (including lists) are replaced.
+//
+// Parameters:
+// - fileName: The name of the configuration file used in error messages.
+// - content: The content of the configuration file.
+// - profile: The name of the profile, or an empty string to decode the configuration without a profile.
+// - overlay: The overlay file of the profile, or nil if there is none.
+//
+// Return:
+// - *Config: The decoded configuration, or nil if the content could not be parsed.
+// - error: ConfigErrors containing every problem found, or nil if the configuration could be decoded.
+func DecodeConfigProfile(fileName string, content []byte, profile string, overlay *ConfigOverlay) (*Config, error) {
+	conf := &Config{file: fileName, positions: make(map[string]Position)}
+	configType := reflect.TypeOf(Config{})
+
+	root, parseError := ParseConfigNode(fileName, content)
+	if parseError != nil {
+		return nil, ConfigErrors{parseError.(ConfigError)}
+	}
+
+	var configErrors ConfigErrors
+	walkConfigNode(root, configType, "", fileName, conf, &configErrors)
+
+	if profile != "" {
+		found := false
+
+		if profileNode := mappingValue(mappingValue(root, "profiles"), profile); profileNode != nil {
+			found = true
+			// record the positions of the profile values under the keys they override, problems were already reported
+			walkConfigNode(profileNode, configType, "", fileName, conf, &ConfigErrors{})
+			MergeConfigNodes(root, profileNode)
+		}
+
+		if overlay != nil {
+			overlayRoot, overlayError := ParseConfigNode(overlay.File, overlay.Content)
+			if overlayError != nil {
+				return nil, append(configErrors, overlayError.(ConfigError))
+			}
+
+			if len(overlayRoot.Content) > 0 {
+				found = true
+				walkConfigNode(overlayRoot, configType, "", overlay.File, conf, &configErrors)
+				MergeConfigNodes(root, overlayRoot)
+			}
+		}
+
+		if !found {
+			configErrors = append(configErrors, conf.errorAt("profiles", "profile '%v' not found", profile))
+		}
+	}
+
+	// unknown keys were already reported by walkConfigNode, so the nodes are decoded without checking for them
+	configErrors = append(configErrors, decodeErrors(fileName, root.Decode(conf))...)
+
+	if len(configErrors) > 0 {
+		return conf, configErrors
+	}
+
+	return conf, nil
+}
+
+// decodeErrors converts an error of the yaml decoder into ConfigErrors.
+func decodeErrors(fileName string, decodeError error) ConfigErrors {
+	if decodeError == nil || errors.Is(decodeError, io.EOF) {
+		return nil
+	}
+
+	var typeError *yaml.TypeError
+	if !errors.As(decodeError, &typeError) {
+		return ConfigErrors{yamlError(fileName, decodeError.Error())}
+	}
+
+	var configErrors ConfigErrors
+	for _, message := range typeError.Errors {
+		// unknown fields were already reported with their column by walkConfigNode
+		if !strings.Contains(message, "not found in type") {
+			configErrors = append(configErrors, yamlError(fileName, message))
+		}
+	}
+	return configErrors
+}
+
+// LoadAndValidateConfig loads a configuration file with a profile like LoadConfigProfile, lets modify change it (e.g. to apply command line overrides)
+// and validates the result. Problems found while decoding and while validating are returned together as ConfigErrors.
+//
+// Parameters:
+// - filePath: The path to the configuration file.
+// - profile: The name of the profile to be applied, or an empty string.
+// - modify: A function applied to the decoded configuration before it is validated, may be nil.
+//
+// Return:
+// - *Config: The valid configuration.
+// - error: An error if the file could not be read, modify failed or the configuration has problems.
+func LoadAndValidateConfig(filePath string, profile string, modify func(conf *Config) error) (*Config, error) {
+	conf, loadError := LoadConfigProfile(filePath, profile)
+
+	var configErrors ConfigErrors
+	if loadError != nil && (conf == nil || !errors.As(loadError, &configErrors)) {
+		return nil, loadError
+	}
+
+	if modify != nil {
+		if modifyError := modify(conf); modifyError != nil {
+			return nil, modifyError
+		}
+	}
+
+	var validationErrors ConfigErrors
+	if validateError := conf.Validate(); errors.As(validateError, &validationErrors) {
+		configErrors = append(configErrors, validationErrors...)
+	}
+
+	if len(configErrors) > 0 {
+		return nil, configErrors
+	}
+
+	return conf, nil
+}
+
+// yamlError converts an error message of the yaml package into a ConfigError with the line of the message.
+func yamlError(fileName string, message string) ConfigError {
+	configError := ConfigError{File: fileName, Message: strings.TrimPrefix(message, "yaml: ")}
+
+	if match := yamlLinePattern.FindStringSubmatch(configError.Message); match != nil {
+		configError.Line, _ = strconv.Atoi(match[1])
+		configError.Column = 1
+		configError.Message = strings.Replace(configError.Message, match[0], "", 1)
+	}
+
+	return configError
+}
+
+// walkConfigNode records the position of every key of the configuration and reports keys that do not exist in the configuration structs.
+// Quoted strings given for booleans and numbers are reported as well, e.g. "yes" in JSON or TOML. Profiles are checked like the configuration itself.
+func walkConfigNode(node *yaml.Node, nodeType reflect.Type, path string, file string, conf *Config, configErrors *ConfigErrors) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	for nodeType.Kind() == reflect.Pointer {
+		nodeType = nodeType.Elem()
+	}
+
+	if nodeType == reflect.TypeOf(yaml.Node{}) {
+		nodeType = reflect.TypeOf(Config{})
+	}
+
+	conf.positions[path] = Position{File: file, Line: node.Line, Column: node.Column}
+
+	switch {
+	case nodeType.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < nodeType.NumField(); i++ {
+			field := nodeType.Field(i)
+			if tag := strings.Split(field.Tag.Get("yaml"), ",")[0]; field.IsExported() && tag != "" && tag != "-" {
+				fields[tag] = field.Type
+			}
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldType, exists := fields[key.Value]
+
+			if !exists {
+				message := fmt.Sprintf("unknown key '%v'", key.Value)
+				if path != "" {
+					message += " in " + path
+				}
+				if suggestion := closestKey(key.Value, SortedKeys(fields)); suggestion != "" {
+					message += fmt.Sprintf(", did you mean '%v'?", suggestion)
+				}
+				*configErrors = append(*configErrors, ConfigError{File: file, Line: key.Line, Column: key.Column, Message: message})
+				continue
+			}
+
+			walkConfigNode(value, fieldType, joinConfigKey(path, key.Value), file, conf, configErrors)
+		}
+	case nodeType.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walkConfigNode(node.Content[i+1], nodeType.Elem(), joinConfigKey(path, node.Content[i].Value), file, conf, configErrors)
+		}
+	case nodeType.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			walkConfigNode(item, nodeType.Elem(), fmt.Sprintf("%v[%v]", path, i), file, conf, configErrors)
+		}
+	case isQuotedString(node) && isScalarKind(nodeType.Kind()):
+		*configErrors = append(*configErrors, ConfigError{File: file, Line: node.Line, Column: node.Column, Message: fmt.Sprintf("%v must be a %v, got the string %q", path, nodeType.Kind(), node.Value)})
+		// the value is reported once, the decoder keeps the default value instead of reporting it again
+		node.Tag, node.Value, node.Style = "!!null", "", 0
+	}
+}
+
+// isQuotedString checks if a node is a quoted string, e.g. a string of a JSON or TOML configuration.
+// Plain YAML scalars like yes are not quoted and are decoded by the rules of YAML.
+func isQuotedString(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!str" && node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0
+}
+
+// isScalarKind checks if a value of a kind is a boolean or a number.
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// joinConfigKey appends a key to a configuration path. Keys containing dots are written in brackets, see SplitConfigKey.
+func joinConfigKey(path string, key string) string {
+	if strings.Contains(key, ".") {
+		return path + "[" + key + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// closestKey returns the known key with the smallest edit distance to an unknown key if it is close enough to be a typo.
+func closestKey(key string, knownKeys []string) string {
+	closest := ""
+	closestDistance := 3
+
+	for _, knownKey := range knownKeys {
+		if distance := editDistance(key, knownKey); distance < closestDistance {
+			closest = knownKey
+			closestDistance = distance
+		}
+	}
+
+	return closest
+}
+
+// editDistance calculates the Levenshtein distance between two strings.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+// errorAt creates a ConfigError located at the position of a configuration key.
+func (conf *Config) errorAt(path string, format string, args ...interface{}) ConfigError {
+	position := conf.positions[path]
+	return ConfigError{File: FirstNonEmpty(position.File, conf.file), Line: position.Line, Column: position.Column, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validate checks the configuration for semantic problems: missing or non-existing input and output locations,
+// invalid option values, combined tables that do not exist and structure names that are used more than once.
+// All problems are reported together.
+//
+// Return:
+// - error: ConfigErrors containing every problem found, or nil if the configuration is valid.
+func (conf *Config) Validate() error {
+	var configErrors ConfigErrors
+	add := func(path string, format string, args ...interface{}) {
+		configErrors = append(configErrors, conf.errorAt(path, format, args...))
+	}
+
+	inputExists := false
+	if conf.InputSchema != "" {
+		// the tables of the input schema are loaded and checked by validateTables
+		if _, statError := os.Stat(conf.InputSchema); statError != nil {
+			add("input_schema", "input schema '%v' does not exist", conf.InputSchema)
+		} else {
+			inputExists = true
+		}
+	} else if strings.TrimSpace(conf.Input) == "" {
+		add("input", "input is required, unless input_schema is set")
+	} else if isDir, _ := IsDir(conf.Input); !isDir {
+		add("input", "input directory '%v' does not exist", conf.Input)
+	} else {
+		inputExists = true
+	}
+
+	if len(conf.Output.Targets()) == 0 {
+		add("output", "no output configured, add output.typescript or output.go")
+	}
+
+	for _, target := range conf.Output.Targets() {
+		dir, file := conf.Output.OutputDir(target), conf.Output.OutputFile(target)
+
+		if strings.TrimSpace(dir) == "" {
+			add("output."+target, "output.%v.output_dir is required", target)
+		} else if info, statError := os.Stat(dir); statError == nil && !info.IsDir() {
+			// missing output directories are created when the files are written
+			add("output."+target+".output_dir", "output directory '%v' is not a directory", dir)
+		}
+
+		if pattern := conf.Output.FilePattern(target); !conf.WritesSingleFile() {
+			if !strings.Contains(pattern, "{name}") && !strings.Contains(pattern, "{table}") {
+				add("output."+target+".file_pattern", "file_pattern '%v' must contain {name} or {table}, otherwise every structure is written into the same file", pattern)
+			}
+		} else if strings.TrimSpace(file) == "" {
+			add("output."+target, "output.%v.output_file is required", target)
+		}
+	}
+
+	for i, pattern := range conf.IgnoreFiles {
+		if patternError := ValidateIgnorePattern(pattern); patternError != nil {
+			add(fmt.Sprintf("ignore_files[%v]", i), "%v", patternError)
+		}
+	}
+
+	for _, key := range SortedKeys(conf.IgnoreColumns) {
+		path := joinConfigKey("ignore_columns", key)
+		if patternError := ValidateIgnorePattern(key); patternError != nil {
+			add(path, "%v", patternError)
+		}
+		for i, pattern := range conf.IgnoreColumns[key] {
+			if patternError := ValidateIgnorePattern(pattern); patternError != nil {
+				add(fmt.Sprintf("%v[%v]", path, i), "%v", patternError)
+			}
+		}
+	}
+
+	for _, key := range SortedKeys(conf.CombineTables) {
+		combiner := conf.CombineTables[key]
+		path := joinConfigKey("combine_tables", key)
+
+		if strings.TrimSpace(combiner.Name) == "" {
+			add(path, "combined structure %v has no name", key)
+		}
+		if len(combiner.Tables) == 0 {
+			add(path, "combined structure %v has no tables", key)
+		}
+		switch combiner.OnCollision {
+		case "", CollisionError, CollisionKeepFirst, CollisionKeepLast, CollisionPrefix:
+		default:
+			add(path+".on_collision", "invalid on_collision '%v', expected error, keep_first, keep_last or prefix", combiner.OnCollision)
+		}
+	}
+
+	for _, target := range SortedKeys(conf.Naming) {
+		naming := conf.Naming[target]
+		path := joinConfigKey("naming", target)
+
+		if target != "typescript" && target != "go" {
+			add(path, "unknown output type '%v' in naming, expected typescript or go", target)
+		}
+		for _, option := range [][2]string{{"types", naming.Types}, {"fields", naming.Fields}} {
+			switch option[1] {
+			case NamingLegacy, NamingPascal, NamingCamel, NamingSnake:
+			default:
+				add(path+"."+option[0], "invalid naming strategy '%v', expected pascal, camel or snake", option[1])
+			}
+		}
+		switch naming.JSONTags {
+		case NamingLegacy, NamingPascal, NamingCamel, NamingSnake, NamingOriginal:
+		default:
+			add(path+".json_tags", "invalid json_tags '%v', expected pascal, camel, snake or original", naming.JSONTags)
+		}
+		switch naming.Escape {
+		case "", EscapeModeSuffix, EscapeModePrefix, EscapeModeQuote:
+		default:
+			add(path+".escape", "invalid escape '%v', expected suffix, prefix or quote", naming.Escape)
+		}
+
+		// the legacy naming title-cases the names as written, options it does not apply are rejected instead of being ignored
+		if naming.Singularize && naming.Types == NamingLegacy {
+			add(path+".singularize", "singularize has no effect with the legacy naming of types, set types to pascal, camel or snake")
+		}
+		if naming.Initialisms || len(naming.ExtraInitialisms) > 0 {
+			capitalized := false
+			for _, strategy := range []string{naming.Types, naming.Fields, naming.JSONTags} {
+				capitalized = capitalized || strategy == NamingPascal || strategy == NamingCamel
+			}
+			option := "initialisms"
+			if !naming.Initialisms {
+				option = "extra_initialisms"
+			}
+			if !capitalized {
+				add(path+"."+option, "initialisms have no effect with the legacy naming, set types, fields or json_tags to pascal or camel")
+			}
+		}
+
+		seen := make(map[string]string)
+		for _, original := range SortedKeys(naming.Rename) {
+			if existing, exists := seen[strings.ToLower(original)]; exists {
+				add(joinConfigKey(path+".rename", original), "rename key '%v' differs from '%v' only in case", original, existing)
+				continue
+			}
+			seen[strings.ToLower(original)] = original
+		}
+	}
+
+	for _, target := range SortedKeys(conf.ArbitraryFields) {
+		for _, key := range SortedKeys(conf.ArbitraryFields[target]) {
+			field := conf.ArbitraryFields[target][key]
+			path := joinConfigKey(joinConfigKey("arbitrary_fields", target), key)
+
+			if strings.TrimSpace(field.Name) == "" {
+				add(path, "arbitrary field %v has no name", key)
+			}
+			if !IsValidPosition(field.Position) {
+				add(path+".position", "invalid position '%v', expected start, end or after:<column>", field.Position)
+			}
+		}
+	}
+
+	if inputExists {
+		configErrors = append(configErrors, conf.validateTables()...)
+	}
+
+	if len(configErrors) > 0 {
+		return configErrors
+	}
+	return nil
+}
+
+// validateTables checks that every table of a combined structure exists in the input directory or the input schema
+// and that no structure name is generated more than once for an output type.
+func (conf *Config) validateTables() ConfigErrors {
+	var configErrors ConfigErrors
+	var tables []ScannedTable
+	source := conf.Input
+
+	if conf.InputSchema != "" {
+		source = conf.InputSchema
+		schema, loadError := LoadSchemaFile(conf.InputSchema)
+		if loadError != nil {
+			return ConfigErrors{conf.errorAt("input_schema", "%v", loadError)}
+		}
+
+		for _, table := range schema.Tables {
+			tables = append(tables, ScannedTable{FileName: table.FileName, Dir: InputFile{Name: table.FileName}.Dir(), TableName: table.OriginalName})
+		}
+	} else {
+		scanned, scanError := ScanTables(conf.Input, conf.InputOptions())
+		if scanError != nil {
+			return ConfigErrors{conf.errorAt("input", "input directory '%v' could not be read: %v", conf.Input, scanError)}
+		}
+		tables = scanned
+	}
+
+	var definitions []SQL
+	// with mirror_input_dirs, the structures of every subdirectory are written into their own output files
+	dirs := make(map[string]string)
+	for _, table := range tables {
+		if FileIgnoreRule(table.FileName, conf.IgnoreFiles) == "" {
+			definitions = append(definitions, SQL{FileName: table.FileName, OriginalName: table.TableName})
+			dirs[table.FileName] = table.Dir
+		}
+	}
+
+	// tables that are only converted as part of a combined structure
+	combinedOnly := make(map[string]bool)
+
+	for _, key := range SortedKeys(conf.CombineTables) {
+		combiner := conf.CombineTables[key]
+		for i, reference := range combiner.Tables {
+			found := false
+			for _, definition := range definitions {
+				if MatchesTable(reference, definition) {
+					found = true
+					if !combiner.ConvertSingleTables {
+						combinedOnly[definition.FileName] = true
+					}
+				}
+			}
+
+			if !found {
+				path := fmt.Sprintf("%v.tables[%v]", joinConfigKey("combine_tables", key), i)
+				configErrors = append(configErrors, conf.errorAt(path, "table '%v' of combined structure %v was not found in %v", reference, key, source))
+			}
+		}
+	}
+
+	for _, target := range conf.Output.Targets() {
+		naming := conf.Naming[target]
+		sources := make(map[string]string)
+
+		for _, definition := range definitions {
+			if combinedOnly[definition.FileName] {
+				continue
+			}
+
+			name := naming.TypeName(definition.OriginalName)
+			key := name
+			if conf.MirrorInputDirs {
+				key = dirs[definition.FileName] + "/" + name
+			}
+
+			source := fmt.Sprintf("table %v (%v)", definition.OriginalName, definition.FileName)
+			if existing, exists := sources[key]; exists {
+				configErrors = append(configErrors, conf.errorAt("input", "structure name '%v' for %v is used by %v and %v", name, target, existing, source))
+				continue
+			}
+			sources[key] = source
+		}
+
+		for _, key := range SortedKeys(conf.CombineTables) {
+			name := conf.CombineTables[key].Name
+			sourceKey := name
+			if conf.MirrorInputDirs {
+				sourceKey = "/" + name
+			}
+
+			source := "combined structure " + key
+			if existing, exists := sources[sourceKey]; exists && name != "" {
+				path := joinConfigKey("combine_tables", key) + ".name"
+				configErrors = append(configErrors, conf.errorAt(path, "structure name '%v' for %v is used by %v and %v", name, target, existing, source))
+				continue
+			}
+			sources[sourceKey] = source
+		}
+	}
+
+	return configErrors
+}
@@ -0,0 +1,65 @@
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeConfigReportsUnknownKeys(t *testing.T) {
+	content := "input: ./sql\noutput:\n  go:\n    ouput_dir: ./out\n    package_name: [a]\nexport_type: true\n"
+
+	_, err := DecodeConfig("s2iconfig.yaml", []byte(content))
+
+	var configErrors ConfigErrors
+	assert.ErrorAs(t, err, &configErrors)
+	assert.Len(t, configErrors, 3)
+	assert.Equal(t, ConfigError{File: "s2iconfig.yaml", Line: 4, Column: 5, Message: "unknown key 'ouput_dir' in output.go, did you mean 'output_dir'?"}, configErrors[0])
+	assert.Equal(t, 6, configErrors[1].Line)
+	assert.Equal(t, 5, configErrors[2].Line)
+}
+
+func TestValidateNaming(t *testing.T) {
+	conf := &Config{Naming: map[string]NamingConfig{
+		"go":         {Singularize: true, Initialisms: true, Rename: map[string]string{"users": "Account", "Users": "Customer"}},
+		"typescript": {Types: NamingPascal, Singularize: true, Initialisms: true, Rename: map[string]string{"users": "Account"}},
+	}}
+
+	var configErrors ConfigErrors
+	assert.ErrorAs(t, conf.Validate(), &configErrors)
+
+	// the input and output are missing as well, only the naming of go is reported
+	var messages []string
+	for _, configError := range configErrors {
+		messages = append(messages, configError.Message)
+	}
+	assert.Equal(t, []string{
+		"input is required, unless input_schema is set",
+		"no output configured, add output.typescript or output.go",
+		"singularize has no effect with the legacy naming of types, set types to pascal, camel or snake",
+		"initialisms have no effect with the legacy naming, set types, fields or json_tags to pascal or camel",
+		"rename key 'users' differs from 'Users' only in case",
+	}, messages)
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "users.sql"), []byte("CREATE TABLE users (id INT)"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "product.sql"), []byte("CREATE TABLE product (id INT)"), 0644))
+
+	content := "input: " + dir + "\n" +
+		"output:\n  go:\n    output_dir: " + dir + "\n    output_file: types.go\n" +
+		"combine_tables:\n  Users:\n    name: Users\n    tables: [product, missing]\n    convert_single_tables: true\n    on_collision: merge\n"
+
+	conf, err := DecodeConfig("s2iconfig.yaml", []byte(content))
+	assert.NoError(t, err)
+
+	var configErrors ConfigErrors
+	assert.ErrorAs(t, conf.Validate(), &configErrors)
+	assert.Len(t, configErrors, 3)
+	assert.Equal(t, ConfigError{File: "s2iconfig.yaml", Line: 11, Column: 19, Message: "invalid on_collision 'merge', expected error, keep_first, keep_last or prefix"}, configErrors[0])
+	assert.Equal(t, ConfigError{File: "s2iconfig.yaml", Line: 9, Column: 23, Message: "table 'missing' of combined structure Users was not found in " + dir}, configErrors[1])
+	assert.Contains(t, configErrors[2].Message, "structure name 'Users' for go is used by table users (users.sql) and combined structure Users")
+}
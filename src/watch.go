@@ -0,0 +1,165 @@
+package src
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileState is the state of a watched file used to detect changes.
+type fileState struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// Watcher detects changes of files by polling their modification time and size.
+// Polling works the same on every platform and does not need any additional dependencies.
+type Watcher struct {
+	Paths    []string
+	Interval time.Duration
+	Debounce time.Duration
+	// Filter selects the files of the watched directories, e.g. Config.InputFilter. Every file is watched if it is nil,
+	// files that are watched directly are never filtered.
+	Filter   func(path string) bool
+	snapshot map[string]fileState
+}
+
+// NewWatcher creates a new Watcher for the given files and directories.
+// Directories are watched with all files they contain, including the files of their subdirectories. Hidden subdirectories are skipped.
+//
+// Parameters:
+// - interval: The time between two scans of the watched paths.
+// - debounce: The time without further changes after which a burst of changes is reported.
+// - paths: The files and directories to be watched.
+//
+// Return:
+// - A pointer to a new Watcher.
+func NewWatcher(interval time.Duration, debounce time.Duration, paths ...string) *Watcher {
+	watcher := &Watcher{
+		Paths:    paths,
+		Interval: interval,
+		Debounce: debounce,
+	}
+	watcher.snapshot = watcher.Scan()
+	return watcher
+}
+
+// Scan returns the current state of all watched files. Paths that cannot be read are skipped.
+func (watcher *Watcher) Scan() map[string]fileState {
+	states := make(map[string]fileState)
+
+	for _, path := range watcher.Paths {
+		info, statError := os.Stat(path)
+		if statError != nil {
+			continue
+		}
+
+		if !info.IsDir() {
+			states[path] = fileState{ModTime: info.ModTime(), Size: info.Size()}
+			continue
+		}
+
+		filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, walkError error) error {
+			if walkError != nil {
+				return nil
+			}
+			if entry.IsDir() {
+				if filePath != path && strings.HasPrefix(entry.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if watcher.Filter != nil && !watcher.Filter(filePath) {
+				return nil
+			}
+
+			// os.Stat follows symlinks, so changes of symlinked files are detected as well
+			entryInfo, infoError := os.Stat(filePath)
+			if infoError != nil || entryInfo.IsDir() {
+				return nil
+			}
+			states[filePath] = fileState{ModTime: entryInfo.ModTime(), Size: entryInfo.Size()}
+			return nil
+		})
+	}
+
+	return states
+}
+
+// InputFilter returns a filter for a Watcher of the input directory (see Watcher.Filter), so only changes of files that are converted trigger a conversion:
+// files with one of the configured extensions that are not ignored by ignore_files.
+func (conf *Config) InputFilter() func(path string) bool {
+	options := conf.InputOptions()
+
+	return func(path string) bool {
+		// ignore_files refers to the files by their path relative to the input directory
+		relative, relativeError := filepath.Rel(conf.Input, path)
+		if relativeError != nil {
+			relative = filepath.Base(path)
+		}
+		_, ignored := MatchIgnoreRules(filepath.ToSlash(relative), conf.IgnoreFiles)
+
+		return options.MatchesExtension(path) && !ignored
+	}
+}
+
+// Changes scans the watched paths and returns the files that were created, modified or deleted since the last scan.
+func (watcher *Watcher) Changes() []string {
+	current := watcher.Scan()
+	var changed []string
+
+	for path, state := range current {
+		if previous, exists := watcher.snapshot[path]; !exists || previous != state {
+			changed = append(changed, path)
+		}
+	}
+
+	for path := range watcher.snapshot {
+		if _, exists := current[path]; !exists {
+			changed = append(changed, path)
+		}
+	}
+
+	watcher.snapshot = current
+	sort.Strings(changed)
+	return changed
+}
+
+// Watch polls the watched paths until the context is cancelled.
+// Changes are collected until no further change happened for the debounce duration, then onChange is called once with all changed files.
+//
+// Parameters:
+// - ctx: The context that stops watching when cancelled.
+// - onChange: The function called with the changed files after every burst of changes.
+func (watcher *Watcher) Watch(ctx context.Context, onChange func(changed []string)) {
+	ticker := time.NewTicker(watcher.Interval)
+	defer ticker.Stop()
+
+	pending := make(map[string]bool)
+	var lastChange time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			changed := watcher.Changes()
+			for _, path := range changed {
+				pending[path] = true
+			}
+			if len(changed) > 0 {
+				lastChange = now
+			}
+
+			if len(pending) > 0 && now.Sub(lastChange) >= watcher.Debounce {
+				onChange(SortedKeys(pending))
+				pending = make(map[string]bool)
+			}
+		}
+	}
+}
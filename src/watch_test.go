@@ -0,0 +1,50 @@
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcherChanges(t *testing.T) {
+	dir := t.TempDir()
+	usersPath := filepath.Join(dir, "users.sql")
+	assert.NoError(t, os.WriteFile(usersPath, []byte("CREATE TABLE users (id INT)"), 0644))
+
+	watcher := NewWatcher(time.Millisecond, time.Millisecond, dir)
+	assert.Empty(t, watcher.Changes())
+
+	ordersPath := filepath.Join(dir, "orders.sql")
+	assert.NoError(t, os.WriteFile(ordersPath, []byte("CREATE TABLE orders (id INT)"), 0644))
+	assert.NoError(t, os.WriteFile(usersPath, []byte("CREATE TABLE users (id INT, name TEXT)"), 0644))
+	assert.Equal(t, []string{ordersPath, usersPath}, watcher.Changes())
+
+	assert.NoError(t, os.Remove(ordersPath))
+	assert.Equal(t, []string{ordersPath}, watcher.Changes())
+
+	invoicesPath := filepath.Join(dir, "billing", "invoices.sql")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(invoicesPath), 0755))
+	assert.NoError(t, os.WriteFile(invoicesPath, []byte("CREATE TABLE invoices (id INT)"), 0644))
+	assert.Equal(t, []string{invoicesPath}, watcher.Changes())
+}
+
+func TestWatcherInputFilter(t *testing.T) {
+	dir := t.TempDir()
+	conf := &Config{Input: dir, IgnoreFiles: []string{"legacy/*"}, IncludeExtensions: []string{".sql", ".ddl"}}
+
+	watcher := NewWatcher(time.Millisecond, time.Millisecond, dir)
+	watcher.Filter = conf.InputFilter()
+
+	usersPath := filepath.Join(dir, "users.ddl")
+	assert.NoError(t, os.WriteFile(usersPath, []byte("CREATE TABLE users (id INT)"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# schema"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "users.sql.swp"), nil, 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "legacy"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "legacy", "orders.sql"), []byte("CREATE TABLE orders (id INT)"), 0644))
+
+	// only files that are converted are reported
+	assert.Equal(t, []string{usersPath}, watcher.Changes())
+}